@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// apiKeyHeader carries API key of the client, keys may be passed in apiKeyParam as well
+	apiKeyHeader = "X-API-Key"
+	apiKeyParam  = "api_key"
+)
+
+// rateLimitHeaders are sent with responses to authenticated requests, so clients can
+// slow down before they are rejected
+var rateLimitHeaders = []string{
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+	"X-RateLimit-Daily-Limit",
+	"X-RateLimit-Daily-Remaining",
+}
+
+// accessControl authenticates API requests and enforces limits of the keys. Limits of
+// a key override default limits when they're set.
+type accessControl struct {
+	keys       KeyStore
+	quotas     *quotas
+	rateLimit  int
+	dailyQuota int
+	now        func() time.Time
+}
+
+func newAccessControl(keys KeyStore, rateLimit int, dailyQuota int) *accessControl {
+	return &accessControl{
+		keys:       keys,
+		quotas:     newQuotas(),
+		rateLimit:  rateLimit,
+		dailyQuota: dailyQuota,
+		now:        time.Now,
+	}
+}
+
+// requestKey returns API key sent with the request
+func requestKey(r *http.Request) string {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+	return r.URL.Query().Get(apiKeyParam)
+}
+
+// setRateLimitHeaders describes limits of the key in response headers
+func setRateLimitHeaders(h http.Header, s quotaStatus, now time.Time) {
+	h.Set("X-RateLimit-Limit", strconv.Itoa(s.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(s.Remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(s.Reset.Unix(), 10))
+	if s.DailyLimit > 0 {
+		h.Set("X-RateLimit-Daily-Limit", strconv.Itoa(s.DailyLimit))
+		h.Set("X-RateLimit-Daily-Remaining", strconv.Itoa(s.DailyRemaining))
+	}
+
+	if !s.Allowed {
+		reset := s.Reset
+		if s.DailyLimit > 0 && s.DailyRemaining == 0 {
+			reset = s.DailyReset
+		}
+		h.Set("Retry-After", strconv.Itoa(int(math.Ceil(reset.Sub(now).Seconds()))))
+	}
+}
+
+// protect allows requests to the handler only with known API key that hasn't exceeded its
+// limits. Handler isn't protected when access control is nil, i.e. authentication is off.
+func (a *accessControl) protect(next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := requestKey(r)
+		if key == "" {
+			writeJSON(w, r, http.StatusUnauthorized, v1Error{"API key is required"})
+			return
+		}
+
+		k, ok := a.keys.Lookup(key)
+		if !ok {
+			writeJSON(w, r, http.StatusUnauthorized, v1Error{"API key is invalid"})
+			return
+		}
+
+		rateLimit, dailyQuota := a.rateLimit, a.dailyQuota
+		if k.RateLimit > 0 {
+			rateLimit = k.RateLimit
+		}
+		if k.DailyQuota > 0 {
+			dailyQuota = k.DailyQuota
+		}
+
+		now := a.now()
+		s := a.quotas.Take(k.Key, rateLimit, dailyQuota, now)
+		setRateLimitHeaders(w.Header(), s, now)
+
+		// Shared caches must not give responses to clients without the key
+		w.Header().Add("Vary", apiKeyHeader)
+
+		logger := requestLogger(r).With("client", k.Name)
+		if !s.Allowed {
+			logger.Info("request limit exceeded", "remaining", s.Remaining, "daily_remaining", s.DailyRemaining)
+			writeJSON(w, r, http.StatusTooManyRequests, v1Error{"request limit exceeded"})
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), loggerKey{}, logger)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rpeshkov/slovnik/phrases"
+)
+
+func TestQuotas(t *testing.T) {
+	q := newQuotas()
+	start := time.Date(2026, 10, 19, 23, 57, 30, 0, time.UTC)
+
+	steps := []struct {
+		at        time.Duration
+		allowed   bool
+		remaining int
+		daily     int
+	}{
+		{0, true, 1, 3},
+		{time.Second, true, 0, 2},
+		{2 * time.Second, false, 0, 2},
+		{30 * time.Second, true, 1, 1},
+		{40 * time.Second, true, 0, 0},
+		{90 * time.Second, false, 2, 0},
+		{150 * time.Second, true, 1, 3},
+	}
+
+	for _, s := range steps {
+		status := q.Take("key", 2, 4, start.Add(s.at))
+		if status.Allowed != s.allowed || status.Remaining != s.remaining || status.DailyRemaining != s.daily {
+			t.Errorf("Take() at +%v == %+v, want allowed %v, remaining %d, daily remaining %d",
+				s.at, status, s.allowed, s.remaining, s.daily)
+		}
+	}
+
+	if s := q.Take("other", 2, 0, start); !s.Allowed || s.DailyLimit != 0 {
+		t.Errorf("Take() of other key == %+v, want allowed without daily limit", s)
+	}
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		content string
+		valid   bool
+	}{
+		{"- key: abc\n  name: frontend\n  rate_limit: 10\n- key: def\n  name: cli\n", true},
+		{"- key: abc\n- key: abc\n", false},
+		{"- name: frontend\n", false},
+		{"- key: abc\n  limit: 10\n", false},
+	}
+
+	for _, c := range cases {
+		path := filepath.Join(dir, "keys.yaml")
+		if err := ioutil.WriteFile(path, []byte(c.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := loadKeyFile(path)
+		if (err == nil) != c.valid {
+			t.Errorf("loadKeyFile(%q) error == %v, want valid %v", c.content, err, c.valid)
+			continue
+		}
+		if !c.valid {
+			continue
+		}
+		if k, ok := s.Lookup("abc"); !ok || k.Name != "frontend" || k.RateLimit != 10 {
+			t.Errorf("Lookup(abc) == %+v, %v, want frontend key", k, ok)
+		}
+		if _, ok := s.Lookup("xyz"); ok {
+			t.Error("Lookup(xyz) found unknown key")
+		}
+	}
+}
+
+type keyList map[string]*APIKey
+
+func (l keyList) Lookup(key string) (*APIKey, bool) {
+	k, ok := l[key]
+	return k, ok
+}
+
+func TestAccessControl(t *testing.T) {
+	keys := keyList{
+		"public":  {Key: "public", Name: "frontend"},
+		"limited": {Key: "limited", Name: "scraper", RateLimit: 1, DailyQuota: 5},
+	}
+	access := newAccessControl(keys, 100, 0)
+	access.now = func() time.Time { return time.Date(2026, 10, 19, 12, 0, 30, 0, time.UTC) }
+
+	d := dictionary{"dobrý": {{Word: "dobrý"}}}
+	router := newRouter(d, d, phrases.NewIndex(0), time.Hour, access)
+
+	cases := []struct {
+		name   string
+		url    string
+		header string
+		status int
+	}{
+		{"no key", "/api/v1/translate?word=dobr%C3%BD", "", http.StatusUnauthorized},
+		{"unknown key", "/api/v1/translate?word=dobr%C3%BD", "secret", http.StatusUnauthorized},
+		{"header key", "/api/v1/translate?word=dobr%C3%BD", "public", http.StatusOK},
+		{"query key", "/api/v1/translate?word=dobr%C3%BD&api_key=public", "", http.StatusOK},
+		{"legacy route", "/api/translate?word=dobr%C3%BD", "", http.StatusUnauthorized},
+		{"first request", "/api/v1/translate?word=dobr%C3%BD", "limited", http.StatusOK},
+		{"exceeded limit", "/api/v1/translate?word=dobr%C3%BD", "limited", http.StatusTooManyRequests},
+		{"open document", "/api/v1/openapi.json", "", http.StatusOK},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest(http.MethodGet, c.url, nil)
+		if c.header != "" {
+			r.Header.Set(apiKeyHeader, c.header)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, r)
+
+		if rec.Code != c.status {
+			t.Errorf("%s: status == %d, want %d", c.name, rec.Code, c.status)
+			continue
+		}
+		if c.status == http.StatusOK || c.url == "/api/v1/openapi.json" {
+			continue
+		}
+
+		body, err := decode(rec.Body.Bytes())
+		if err != nil {
+			t.Errorf("%s: bad JSON: %v", c.name, err)
+			continue
+		}
+		if err = responseSchema(t, "/translate", c.status).Validate(body); err != nil {
+			t.Errorf("%s: response doesn't match schema: %v", c.name, err)
+		}
+	}
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	keys := keyList{"limited": {Key: "limited", RateLimit: 2, DailyQuota: 10}}
+	access := newAccessControl(keys, 100, 0)
+	now := time.Date(2026, 10, 19, 12, 0, 45, 0, time.UTC)
+	access.now = func() time.Time { return now }
+
+	handler := access.protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	expected := []map[string]string{
+		{"X-RateLimit-Limit": "2", "X-RateLimit-Remaining": "1", "X-RateLimit-Daily-Remaining": "9", "Retry-After": ""},
+		{"X-RateLimit-Limit": "2", "X-RateLimit-Remaining": "0", "X-RateLimit-Daily-Remaining": "8", "Retry-After": ""},
+		{"X-RateLimit-Limit": "2", "X-RateLimit-Remaining": "0", "X-RateLimit-Daily-Remaining": "8", "Retry-After": "15"},
+	}
+
+	for i, headers := range expected {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/translate?word=slovo&api_key=limited", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+
+		for name, value := range headers {
+			if got := rec.Header().Get(name); got != value {
+				t.Errorf("request #%d header %s == %q, want %q", i+1, name, got, value)
+			}
+		}
+		if reset := rec.Header().Get("X-RateLimit-Reset"); reset != "1792411260" {
+			t.Errorf("request #%d X-RateLimit-Reset == %s, want end of minute", i+1, reset)
+		}
+	}
+}
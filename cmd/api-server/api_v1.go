@@ -0,0 +1,298 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rpeshkov/slovnik"
+	"github.com/rpeshkov/slovnik/phrases"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// openAPIv1 describes routes of API v1, it must be updated together with types below
+//
+//go:embed openapi.json
+var openAPIv1 []byte
+
+// Number of suggestions or phrases returned when client doesn't ask for other amount, and
+// the most it may ask for
+const (
+	defaultLimit = 10
+	maxLimit     = 50
+)
+
+// Kinds of translation results
+const (
+	// kindEntry means that the word is found in the dictionary
+	kindEntry = "entry"
+
+	// kindSuggestions means that the word isn't found and similar words are suggested
+	kindSuggestions = "suggestions"
+
+	// kindNotFound means that nothing is found
+	kindNotFound = "not_found"
+)
+
+// v1Sample is a phrase with the word
+type v1Sample struct {
+	Keyword     string `json:"keyword"`
+	Phrase      string `json:"phrase"`
+	Translation string `json:"translation"`
+}
+
+// v1Word is a dictionary entry or a suggestion
+type v1Word struct {
+	Word         string     `json:"word"`
+	Lemma        string     `json:"lemma,omitempty"`
+	Type         string     `json:"type,omitempty"`
+	Translations []string   `json:"translations"`
+	Synonyms     []string   `json:"synonyms"`
+	Antonyms     []string   `json:"antonyms"`
+	DerivedWords []string   `json:"derivedWords"`
+	Samples      []v1Sample `json:"samples"`
+}
+
+// v1Translation is a response of translate route
+type v1Translation struct {
+	Query    string   `json:"query"`
+	Language string   `json:"language"`
+	Kind     string   `json:"kind"`
+	Words    []v1Word `json:"words"`
+}
+
+// v1Suggestions is a response of suggest route
+type v1Suggestions struct {
+	Prefix   string   `json:"prefix"`
+	Language string   `json:"language"`
+	Words    []string `json:"words"`
+}
+
+// v1Phrase is a sample phrase found by search
+type v1Phrase struct {
+	Headword    string  `json:"headword"`
+	Language    string  `json:"language"`
+	Phrase      string  `json:"phrase"`
+	Translation string  `json:"translation"`
+	Score       float64 `json:"score"`
+}
+
+// v1PhraseSearch is a response of phrase search route
+type v1PhraseSearch struct {
+	Query   string     `json:"query"`
+	Phrases []v1Phrase `json:"phrases"`
+}
+
+// v1Error is a response of failed request
+type v1Error struct {
+	Error string `json:"error"`
+}
+
+// nonNil returns empty list instead of nil, so lists are never null in responses
+func nonNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
+
+// newV1Word converts dictionary word to its API representation
+func newV1Word(w *slovnik.Word) v1Word {
+	samples := make([]v1Sample, len(w.Samples))
+	for i, s := range w.Samples {
+		samples[i] = v1Sample{Keyword: s.Keyword, Phrase: s.Phrase, Translation: s.Translation}
+	}
+
+	return v1Word{
+		Word:         w.Word,
+		Lemma:        w.Lemma,
+		Type:         w.WordType,
+		Translations: nonNil(w.Translations),
+		Synonyms:     nonNil(w.Synonyms),
+		Antonyms:     nonNil(w.Antonyms),
+		DerivedWords: nonNil(w.DerivedWords),
+		Samples:      samples,
+	}
+}
+
+// newV1Translation converts translation result to its API representation
+func newV1Translation(query string, lang slovnik.Language, words []*slovnik.Word) v1Translation {
+	t := v1Translation{
+		Query:    query,
+		Language: lang.String(),
+		Words:    make([]v1Word, len(words)),
+	}
+	for i, w := range words {
+		t.Words[i] = newV1Word(w)
+	}
+
+	switch {
+	case len(words) == 0:
+		t.Kind = kindNotFound
+	case slovnik.IsEntry(words, query):
+		t.Kind = kindEntry
+	default:
+		t.Kind = kindSuggestions
+	}
+	return t
+}
+
+// requestLimit returns number of results requested by limit query parameter
+func requestLimit(r *http.Request) (int, error) {
+	l := r.URL.Query().Get("limit")
+	if l == "" {
+		return defaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(l)
+	if err != nil || limit < 1 || limit > maxLimit {
+		return 0, fmt.Errorf("limit must be from 1 to %d", maxLimit)
+	}
+	return limit, nil
+}
+
+// writeJSON writes the value as JSON response with provided status
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		requestLogger(r).Warn("unable to write response", "err", err)
+	}
+}
+
+// registerV1 registers routes of API v1 in the router of /api/v1 prefix. OpenAPI document
+// is available without API key.
+func registerV1(router *mux.Router, translator slovnik.Translator, suggester slovnik.Suggester, index *phrases.Index, cacheMaxAge time.Duration, access *accessControl) {
+	router.
+		Methods(http.MethodGet).
+		Path("/translate").
+		Handler(access.protect(translateV1(translator, cacheMaxAge)))
+
+	router.
+		Methods(http.MethodGet).
+		Path("/suggest").
+		Handler(access.protect(suggestV1(suggester)))
+
+	router.
+		Methods(http.MethodGet).
+		Path("/phrases/search").
+		Handler(access.protect(searchPhrasesV1(index)))
+
+	router.
+		Methods(http.MethodGet).
+		Path("/openapi.json").
+		HandlerFunc(openAPIHandler)
+}
+
+func translateV1(translator slovnik.Translator, cacheMaxAge time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		word := r.URL.Query().Get("word")
+		if word == "" {
+			writeJSON(w, r, http.StatusBadRequest, v1Error{"word is required"})
+			return
+		}
+
+		lang, err := requestLanguage(r, word)
+		if err != nil {
+			writeJSON(w, r, http.StatusBadRequest, v1Error{err.Error()})
+			return
+		}
+
+		words, err := lookup(r, translator, word, lang)
+		if err != nil {
+			// Failures are temporary, so they must not be cached by proxies
+			w.Header().Set("Cache-Control", "no-store")
+			writeJSON(w, r, http.StatusBadGateway, v1Error{"dictionary is unavailable"})
+			return
+		}
+
+		if notModified(w, r, wordsETag(words), resultMaxAge(words, cacheMaxAge)) {
+			return
+		}
+
+		writeJSON(w, r, http.StatusOK, newV1Translation(word, lang, words))
+	}
+}
+
+// suggestV1 suggests words for partially typed prefix
+func suggestV1(suggester slovnik.Suggester) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		prefix := strings.TrimSpace(r.URL.Query().Get("prefix"))
+		if prefix == "" {
+			writeJSON(w, r, http.StatusBadRequest, v1Error{"prefix is required"})
+			return
+		}
+
+		lang, err := requestLanguage(r, prefix)
+		if err != nil {
+			writeJSON(w, r, http.StatusBadRequest, v1Error{err.Error()})
+			return
+		}
+
+		limit, err := requestLimit(r)
+		if err != nil {
+			writeJSON(w, r, http.StatusBadRequest, v1Error{err.Error()})
+			return
+		}
+
+		ctx, span := tracer.Start(r.Context(), "api.suggest", trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attribute.String("prefix", prefix), attribute.String("direction", lang.String())))
+		defer span.End()
+
+		words, err := suggester.Suggest(ctx, prefix, lang, limit)
+		if err != nil {
+			requestLogger(r).Error("suggestion failed", "prefix", prefix, "direction", lang.String(), "err", err)
+			span.SetStatus(codes.Error, err.Error())
+			w.Header().Set("Cache-Control", "no-store")
+			writeJSON(w, r, http.StatusBadGateway, v1Error{"dictionary is unavailable"})
+			return
+		}
+
+		w.Header().Set("Cache-Control", cacheControl(suggestMaxAge))
+		writeJSON(w, r, http.StatusOK, v1Suggestions{Prefix: prefix, Language: lang.String(), Words: nonNil(words)})
+	}
+}
+
+// searchPhrasesV1 finds sample phrases of entries looked up before
+func searchPhrasesV1(index *phrases.Index) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			writeJSON(w, r, http.StatusBadRequest, v1Error{"q is required"})
+			return
+		}
+
+		limit, err := requestLimit(r)
+		if err != nil {
+			writeJSON(w, r, http.StatusBadRequest, v1Error{err.Error()})
+			return
+		}
+
+		matches := index.Search(query, limit)
+		result := v1PhraseSearch{Query: query, Phrases: make([]v1Phrase, len(matches))}
+		for i, m := range matches {
+			result.Phrases[i] = v1Phrase{
+				Headword:    m.Headword,
+				Language:    m.Language.String(),
+				Phrase:      m.Phrase.Phrase,
+				Translation: m.Translation,
+				Score:       m.Score,
+			}
+		}
+
+		requestLogger(r).Debug("phrases found", "query", query, "results", len(matches))
+		writeJSON(w, r, http.StatusOK, result)
+	}
+}
+
+func openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPIv1)
+}
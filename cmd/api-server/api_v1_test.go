@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rpeshkov/slovnik"
+	"github.com/rpeshkov/slovnik/phrases"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// dictionary returns words that are stored for the word, unknown words fail
+type dictionary map[string][]*slovnik.Word
+
+func (d dictionary) Translate(ctx context.Context, word string, language slovnik.Language) ([]*slovnik.Word, error) {
+	words, ok := d[word]
+	if !ok {
+		return nil, errors.New("upstream failed")
+	}
+	return words, nil
+}
+
+// Suggest returns stored words that start with the prefix, prefix "failing" fails
+func (d dictionary) Suggest(ctx context.Context, prefix string, language slovnik.Language, limit int) ([]string, error) {
+	if prefix == "failing" {
+		return nil, errors.New("upstream failed")
+	}
+
+	var words []string
+	for w := range d {
+		if strings.HasPrefix(w, prefix) {
+			words = append(words, w)
+		}
+	}
+	sort.Strings(words)
+	if len(words) > limit {
+		words = words[:limit]
+	}
+	return words, nil
+}
+
+// decode unmarshals JSON the way schema validator expects it
+func decode(data []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	err := d.Decode(&v)
+	return v, err
+}
+
+// responseSchema compiles schema of the response documented in OpenAPI document
+func responseSchema(t *testing.T, path string, status int) *jsonschema.Schema {
+	c := jsonschema.NewCompiler()
+	c.Draft = jsonschema.Draft2020
+	if err := c.AddResource("openapi.json", bytes.NewReader(openAPIv1)); err != nil {
+		t.Fatalf("bad OpenAPI document: %v", err)
+	}
+
+	escape := strings.NewReplacer("~", "~0", "/", "~1")
+	pointer := "openapi.json#/paths/" + escape.Replace(path) + "/get/responses/" + strconv.Itoa(status) +
+		"/content/application~1json/schema"
+	schema, err := c.Compile(pointer)
+	if err != nil {
+		t.Fatalf("response %d of %s isn't documented: %v", status, path, err)
+	}
+	return schema
+}
+
+func TestV1ResponsesMatchSchema(t *testing.T) {
+	translator := dictionary{
+		"dobrý": {{
+			Word:         "dobrý",
+			Translations: []string{"хороший"},
+			WordType:     "přídavné jméno",
+			Samples:      []slovnik.SampleUse{{Keyword: "dobrý", Phrase: "dobrý den", Translation: "добрый день"}},
+		}},
+		"dobr":   {{Word: "dobrý", Translations: []string{"хороший"}}, {Word: "dobro", Translations: []string{"добро"}}},
+		"dobrta": {{Word: "dobrat", Translations: []string{"добрать"}}},
+		"xyzzy":  {},
+		"слово":  {{Word: "слово", Translations: []string{"slovo"}, Lemma: "слово"}},
+	}
+	router := newRouter(translator, translator, phrases.NewIndex(0), time.Hour, nil)
+
+	cases := []struct {
+		query  string
+		status int
+		kind   string
+	}{
+		{"word=dobr%C3%BD", http.StatusOK, kindEntry},
+		{"word=dobr", http.StatusOK, kindSuggestions},
+		{"word=dobrta", http.StatusOK, kindSuggestions},
+		{"word=xyzzy&lang=cs", http.StatusOK, kindNotFound},
+		{"word=%D1%81%D0%BB%D0%BE%D0%B2%D0%BE&lang=ru", http.StatusOK, kindEntry},
+		{"", http.StatusBadRequest, ""},
+		{"word=dobr%C3%BD&lang=de", http.StatusBadRequest, ""},
+		{"word=failing", http.StatusBadGateway, ""},
+	}
+
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/translate?"+c.query, nil))
+
+		if rec.Code != c.status {
+			t.Errorf("GET /translate?%s status == %d, want %d", c.query, rec.Code, c.status)
+			continue
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+			t.Errorf("GET /translate?%s Content-Type == %q, want JSON", c.query, ct)
+		}
+
+		body, err := decode(rec.Body.Bytes())
+		if err != nil {
+			t.Errorf("GET /translate?%s returned bad JSON: %v", c.query, err)
+			continue
+		}
+		if err = responseSchema(t, "/translate", c.status).Validate(body); err != nil {
+			t.Errorf("GET /translate?%s response doesn't match schema: %v", c.query, err)
+		}
+
+		if c.kind != "" {
+			if kind := body.(map[string]interface{})["kind"]; kind != c.kind {
+				t.Errorf("GET /translate?%s kind == %v, want %s", c.query, kind, c.kind)
+			}
+		}
+	}
+}
+
+func TestV1Suggest(t *testing.T) {
+	d := dictionary{"dobrý": nil, "dobro": nil, "doba": nil, "слово": nil}
+	router := newRouter(d, d, phrases.NewIndex(0), time.Hour, nil)
+
+	cases := []struct {
+		query  string
+		status int
+		words  []string
+	}{
+		{"prefix=dob", http.StatusOK, []string{"doba", "dobro", "dobrý"}},
+		{"prefix=dob&limit=2", http.StatusOK, []string{"doba", "dobro"}},
+		{"prefix=%D1%81%D0%BB&lang=ru", http.StatusOK, []string{"слово"}},
+		{"prefix=xyz", http.StatusOK, []string{}},
+		{"prefix=dob&limit=0", http.StatusBadRequest, nil},
+		{"prefix=dob&limit=100", http.StatusBadRequest, nil},
+		{"prefix=dob&lang=de", http.StatusBadRequest, nil},
+		{"prefix=+", http.StatusBadRequest, nil},
+		{"prefix=failing", http.StatusBadGateway, nil},
+	}
+
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/suggest?"+c.query, nil))
+
+		if rec.Code != c.status {
+			t.Errorf("GET /suggest?%s status == %d, want %d", c.query, rec.Code, c.status)
+			continue
+		}
+
+		body, err := decode(rec.Body.Bytes())
+		if err != nil {
+			t.Errorf("GET /suggest?%s returned bad JSON: %v", c.query, err)
+			continue
+		}
+		if err = responseSchema(t, "/suggest", c.status).Validate(body); err != nil {
+			t.Errorf("GET /suggest?%s response doesn't match schema: %v", c.query, err)
+		}
+
+		if c.status == http.StatusOK && rec.Header().Get("Cache-Control") != cacheControl(suggestMaxAge) {
+			t.Errorf("GET /suggest?%s Cache-Control == %q, want %q", c.query, rec.Header().Get("Cache-Control"), cacheControl(suggestMaxAge))
+		}
+
+		if c.words != nil {
+			var s v1Suggestions
+			json.Unmarshal(rec.Body.Bytes(), &s)
+			if !reflect.DeepEqual(s.Words, c.words) {
+				t.Errorf("GET /suggest?%s words == %q, want %q", c.query, s.Words, c.words)
+			}
+		}
+	}
+}
+
+func TestV1SearchPhrases(t *testing.T) {
+	d := dictionary{
+		"dobrý": {{
+			Word: "dobrý",
+			Samples: []slovnik.SampleUse{
+				{Keyword: "dobrý", Phrase: "dobrý den", Translation: "до́брый день"},
+				{Keyword: "dobrý", Phrase: "dobrý večer", Translation: "до́брый ве́чер"},
+			},
+		}},
+	}
+	index := phrases.NewIndex(0)
+	router := newRouter(phrases.NewTranslator(d, index), d, index, time.Hour, nil)
+
+	get := func(url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		return rec
+	}
+
+	if rec := get("/api/v1/phrases/search?q=dobry"); !strings.Contains(rec.Body.String(), `"phrases":[]`) {
+		t.Errorf("search before lookup == %s, want no phrases", rec.Body.String())
+	}
+	get("/api/v1/translate?word=dobr%C3%BD")
+
+	cases := []struct {
+		query   string
+		status  int
+		phrases []string
+	}{
+		{"q=DOBRY+den", http.StatusOK, []string{"dobrý den"}},
+		{"q=%D0%B2%D0%B5%D1%87%D0%B5%D1%80", http.StatusOK, []string{"dobrý večer"}},
+		{"q=dobry&limit=1", http.StatusOK, []string{"dobrý den"}},
+		{"q=noc", http.StatusOK, []string{}},
+		{"q=", http.StatusBadRequest, nil},
+		{"q=dobry&limit=x", http.StatusBadRequest, nil},
+	}
+
+	for _, c := range cases {
+		rec := get("/api/v1/phrases/search?" + c.query)
+		if rec.Code != c.status {
+			t.Errorf("GET /phrases/search?%s status == %d, want %d", c.query, rec.Code, c.status)
+			continue
+		}
+
+		body, err := decode(rec.Body.Bytes())
+		if err != nil {
+			t.Errorf("GET /phrases/search?%s returned bad JSON: %v", c.query, err)
+			continue
+		}
+		if err = responseSchema(t, "/phrases/search", c.status).Validate(body); err != nil {
+			t.Errorf("GET /phrases/search?%s response doesn't match schema: %v", c.query, err)
+		}
+
+		if c.phrases != nil {
+			var s v1PhraseSearch
+			json.Unmarshal(rec.Body.Bytes(), &s)
+			found := []string{}
+			for _, p := range s.Phrases {
+				found = append(found, p.Phrase)
+			}
+			if !reflect.DeepEqual(found, c.phrases) {
+				t.Errorf("GET /phrases/search?%s phrases == %q, want %q", c.query, found, c.phrases)
+			}
+		}
+	}
+}
+
+func TestV1OpenAPIDocument(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newRouter(dictionary{}, dictionary{}, phrases.NewIndex(0), time.Hour, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+
+	if rec.Code != http.StatusOK || !bytes.Equal(rec.Body.Bytes(), openAPIv1) {
+		t.Errorf("GET /openapi.json == %d %q, want the document", rec.Code, rec.Body.String())
+	}
+
+	doc, err := decode(rec.Body.Bytes())
+	if err != nil {
+		t.Fatalf("bad OpenAPI document: %v", err)
+	}
+	if err = responseSchema(t, "/openapi.json", http.StatusOK).Validate(doc); err != nil {
+		t.Errorf("OpenAPI document doesn't match its schema: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rpeshkov/slovnik"
+	"github.com/rpeshkov/slovnik/metrics"
+)
+
+// notFoundMaxAge limits the time empty results may be cached. Word may be missing because
+// of a temporary failure of the dictionary, so it shouldn't stay "not found" for long.
+const notFoundMaxAge = 5 * time.Minute
+
+// Suggestions are asked on every keystroke, so they're kept for a short time by the server
+// and by clients. Words looked up locally or added to the dictionary appear after that.
+const (
+	suggestMaxAge    = 5 * time.Minute
+	suggestCacheSize = 10000
+)
+
+// resultMaxAge returns the time translation result may be cached
+func resultMaxAge(words []*slovnik.Word, maxAge time.Duration) time.Duration {
+	if len(words) == 0 && maxAge > notFoundMaxAge {
+		return notFoundMaxAge
+	}
+	return maxAge
+}
+
+// wordsETag returns strong entity tag of translation result. Words are serialised with
+// fixed field order, so the same entry always gets the same tag.
+func wordsETag(words []*slovnik.Word) string {
+	// Words contain only strings, so they're always serialised
+	data, _ := json.Marshal(words)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// cacheControl returns Cache-Control value that allows to keep responses for maxAge.
+// Responses are revalidated every time when maxAge is zero.
+func cacheControl(maxAge time.Duration) string {
+	if maxAge <= 0 {
+		return "no-cache"
+	}
+	return fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+}
+
+// etagMatches reports whether If-None-Match header contains the tag. Tags are compared
+// weakly, as required for GET requests.
+func etagMatches(header string, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified sets caching headers of the response and reports whether client already has
+// it. Not Modified status is written in that case and handler must not write the body.
+func notModified(w http.ResponseWriter, r *http.Request, etag string, maxAge time.Duration) bool {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl(maxAge))
+
+	if header := r.Header.Get("If-None-Match"); header != "" && etagMatches(header, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+type suggestKey struct {
+	prefix   string
+	language slovnik.Language
+	limit    int
+}
+
+type suggestEntry struct {
+	words   []string
+	expires time.Time
+}
+
+// cachedSuggester keeps suggestions for ttl, so the dictionary isn't asked again when users
+// type the same prefixes. Failures aren't cached. Not more than size lists are kept, new
+// ones aren't cached until old ones expire.
+type cachedSuggester struct {
+	suggester slovnik.Suggester
+	ttl       time.Duration
+	size      int
+
+	mu      sync.Mutex
+	entries map[suggestKey]suggestEntry
+}
+
+func newCachedSuggester(suggester slovnik.Suggester, ttl time.Duration, size int) *cachedSuggester {
+	return &cachedSuggester{
+		suggester: suggester,
+		ttl:       ttl,
+		size:      size,
+		entries:   make(map[suggestKey]suggestEntry),
+	}
+}
+
+// Suggest returns cached suggestions or asks the suggester
+func (c *cachedSuggester) Suggest(ctx context.Context, prefix string, language slovnik.Language, limit int) ([]string, error) {
+	key := suggestKey{prefix, language, limit}
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if ok && time.Now().Before(e.expires) {
+		metrics.CacheLookup("suggest", true)
+		return e.words, nil
+	}
+	metrics.CacheLookup("suggest", false)
+
+	words, err := c.suggester.Suggest(ctx, prefix, language, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= c.size {
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	if len(c.entries) < c.size {
+		c.entries[key] = suggestEntry{words, now.Add(c.ttl)}
+	}
+
+	return words, nil
+}
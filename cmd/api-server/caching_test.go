@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rpeshkov/slovnik"
+	"github.com/rpeshkov/slovnik/phrases"
+)
+
+func TestEtagMatches(t *testing.T) {
+	cases := []struct {
+		header string
+		etag   string
+		match  bool
+	}{
+		{`"abc"`, `"abc"`, true},
+		{`W/"abc"`, `"abc"`, true},
+		{`"xyz", "abc"`, `"abc"`, true},
+		{`*`, `"abc"`, true},
+		{`"xyz"`, `"abc"`, false},
+		{`abc`, `"abc"`, false},
+	}
+
+	for _, c := range cases {
+		if match := etagMatches(c.header, c.etag); match != c.match {
+			t.Errorf("etagMatches(%q, %q) == %v, want %v", c.header, c.etag, match, c.match)
+		}
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	cases := []struct {
+		maxAge   time.Duration
+		expected string
+	}{
+		{24 * time.Hour, "public, max-age=86400"},
+		{90 * time.Second, "public, max-age=90"},
+		{0, "no-cache"},
+	}
+
+	for _, c := range cases {
+		if got := cacheControl(c.maxAge); got != c.expected {
+			t.Errorf("cacheControl(%v) == %q, want %q", c.maxAge, got, c.expected)
+		}
+	}
+}
+
+func TestConditionalGet(t *testing.T) {
+	d := dictionary{
+		"dobrý": {{Word: "dobrý", Translations: []string{"хороший"}}},
+		"dobro": {{Word: "dobro", Translations: []string{"добро"}}},
+		"xyzzy": {},
+	}
+	router := newRouter(d, d, phrases.NewIndex(0), time.Hour, nil)
+
+	get := func(url string, etag string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, url, nil)
+		if etag != "" {
+			r.Header.Set("If-None-Match", etag)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, r)
+		return rec
+	}
+
+	for _, url := range []string{"/api/v1/translate?word=dobr%C3%BD", "/api/translate?word=dobr%C3%BD"} {
+		first := get(url, "")
+		etag := first.Header().Get("ETag")
+		if first.Code != http.StatusOK || etag == "" {
+			t.Errorf("GET %s == %d with ETag %q, want 200 with ETag", url, first.Code, etag)
+			continue
+		}
+		if cc := first.Header().Get("Cache-Control"); cc != "public, max-age=3600" {
+			t.Errorf("GET %s Cache-Control == %q, want public, max-age=3600", url, cc)
+		}
+
+		cached := get(url, etag)
+		if cached.Code != http.StatusNotModified || cached.Body.Len() != 0 {
+			t.Errorf("GET %s with matching ETag == %d %q, want 304 without body", url, cached.Code, cached.Body.String())
+		}
+		if cached.Header().Get("ETag") != etag {
+			t.Errorf("GET %s with matching ETag has ETag %q, want %q", url, cached.Header().Get("ETag"), etag)
+		}
+
+		if stale := get(url, `"stale"`); stale.Code != http.StatusOK {
+			t.Errorf("GET %s with stale ETag == %d, want 200", url, stale.Code)
+		}
+	}
+
+	if a, b := wordsETag([]*slovnik.Word{{Word: "dobrý"}}), wordsETag([]*slovnik.Word{{Word: "dobro"}}); a == b {
+		t.Errorf("wordsETag() == %s for different words", a)
+	}
+
+	for _, url := range []string{"/api/v1/translate?word=xyzzy", "/api/translate?word=xyzzy"} {
+		if cc := get(url, "").Header().Get("Cache-Control"); cc != "public, max-age=300" {
+			t.Errorf("GET %s without results Cache-Control == %q, want public, max-age=300", url, cc)
+		}
+	}
+
+	if failed := get("/api/v1/translate?word=unknown", ""); failed.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("failed GET Cache-Control == %q, want no-store", failed.Header().Get("Cache-Control"))
+	}
+}
+
+// countingSuggester suggests the prefix itself and counts the calls
+type countingSuggester struct {
+	calls int
+}
+
+func (s *countingSuggester) Suggest(ctx context.Context, prefix string, language slovnik.Language, limit int) ([]string, error) {
+	s.calls++
+	if prefix == "failing" {
+		return nil, errors.New("dictionary is unavailable")
+	}
+	return []string{prefix}, nil
+}
+
+func TestCachedSuggester(t *testing.T) {
+	upstream := &countingSuggester{}
+	cache := newCachedSuggester(upstream, time.Hour, 3)
+
+	cases := []struct {
+		prefix string
+		limit  int
+		calls  int
+	}{
+		{"dob", 10, 1},
+		{"dob", 10, 1},
+		{"dob", 5, 2},
+		{"failing", 10, 3},
+		{"failing", 10, 4},
+		{"pes", 10, 5},
+		// Cache is full, new prefixes are asked every time
+		{"kočka", 10, 6},
+		{"kočka", 10, 7},
+		{"dob", 10, 7},
+	}
+
+	for _, c := range cases {
+		words, err := cache.Suggest(context.Background(), c.prefix, slovnik.Cz, c.limit)
+		if c.prefix != "failing" && (err != nil || len(words) != 1 || words[0] != c.prefix) {
+			t.Errorf("Suggest(%q) == %q, %v, want suggestions of upstream", c.prefix, words, err)
+		}
+		if upstream.calls != c.calls {
+			t.Errorf("upstream is called %d times after Suggest(%q, %d), want %d", upstream.calls, c.prefix, c.limit, c.calls)
+		}
+	}
+
+	// Expired lists make room for new ones
+	for k, e := range cache.entries {
+		e.expires = time.Now().Add(-time.Second)
+		cache.entries[k] = e
+	}
+	cache.Suggest(context.Background(), "kočka", slovnik.Cz, 10)
+	cache.Suggest(context.Background(), "kočka", slovnik.Cz, 10)
+	if upstream.calls != 8 {
+		t.Errorf("upstream is called %d times, want expired lists to be replaced", upstream.calls)
+	}
+}
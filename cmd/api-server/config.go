@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rpeshkov/slovnik/config"
+)
+
+// Config represents configuration of API server
+type Config struct {
+	config.Upstream
+	config.Logging
+	config.Tracing
+
+	// Listen is an address of HTTP server
+	Listen string `key:"listen" default:":8080" desc:"Address of HTTP server"`
+
+	// MetricsListen is an address of HTTP server of metrics. They're kept off the API, so
+	// they're served without API keys and only where they can be scraped.
+	MetricsListen string `key:"metrics_listen" default:":9090" desc:"Address of HTTP server of metrics, they aren't served when it's empty"`
+
+	// ReadTimeout, WriteTimeout and IdleTimeout limit the time of reading request, writing
+	// response and waiting for the next request on keep-alive connection
+	ReadTimeout  time.Duration `key:"read_timeout" default:"10s" min:"1ms" desc:"Time allowed to read request"`
+	WriteTimeout time.Duration `key:"write_timeout" default:"30s" min:"1ms" desc:"Time allowed to handle request and write response"`
+	IdleTimeout  time.Duration `key:"idle_timeout" default:"2m" min:"1ms" desc:"Time keep-alive connection waits for the next request"`
+
+	// ShutdownTimeout limits the time given to in-flight requests on shutdown
+	ShutdownTimeout time.Duration `key:"shutdown_timeout" default:"15s" desc:"Time given to in-flight requests on shutdown"`
+
+	// ShutdownDelay is a time between becoming unready and closing the listener, so load
+	// balancers notice it and stop sending new requests
+	ShutdownDelay time.Duration `key:"shutdown_delay" default:"5s" desc:"Time the server keeps accepting requests after it becomes unready on shutdown"`
+
+	// ReadyProbeWord is translated to check readiness. Readiness doesn't depend on the
+	// dictionary when it's empty.
+	ReadyProbeWord     string        `key:"ready_probe_word" example:"slovo" desc:"Word translated to check that dictionary is available, it isn't checked when empty"`
+	ReadyProbeInterval time.Duration `key:"ready_probe_interval" default:"30s" desc:"Time result of dictionary check is kept"`
+	ReadyProbeTimeout  time.Duration `key:"ready_probe_timeout" default:"5s" min:"1ms" desc:"Time given to dictionary check"`
+
+	// Suggestions is a source of suggestions for partially typed words. Upstream asks the
+	// dictionary on every request, local suggests only words looked up since start.
+	Suggestions string `key:"suggestions" default:"upstream" desc:"Source of suggestions: upstream asks the dictionary, local uses words looked up before"`
+
+	// MaxSuggestWords is a number of words kept in memory for local suggestions in each
+	// language. Words looked up after that aren't suggested.
+	MaxSuggestWords int `key:"max_suggest_words" default:"100000" min:"0" desc:"Number of words kept for local suggestions in each language, zero means no limit"`
+
+	// PhrasesLimit is a number of phrases kept in memory for search. Phrases of entries
+	// looked up after that aren't searched.
+	PhrasesLimit int `key:"phrases_limit" default:"100000" min:"0" desc:"Number of phrases kept for search, zero means no limit"`
+
+	// CacheMaxAge is a time translations may be kept by browsers and proxies. Dictionary
+	// entries rarely change, cached copies are revalidated by ETag after that.
+	CacheMaxAge time.Duration `key:"cache_max_age" default:"24h" desc:"Time translations may be cached by browsers and proxies"`
+
+	// APIKeys is a path to YAML file with keys of clients. API is open to everyone when
+	// it's empty.
+	APIKeys string `key:"api_keys" desc:"YAML file with API keys, API is open to everyone when it's empty"`
+
+	// RateLimit and DailyQuota apply to the keys that don't set their own limits
+	RateLimit  int `key:"rate_limit" default:"60" min:"1" desc:"Requests per minute allowed for one API key"`
+	DailyQuota int `key:"daily_quota" default:"0" min:"0" desc:"Requests per day allowed for one API key, zero means unlimited"`
+
+	// CORSOrigins are origins of web pages that may call API
+	CORSOrigins []string `key:"cors_origins" default:"*" desc:"Comma separated origins allowed to call API from browsers, * allows any"`
+}
+
+// Sources of suggestions
+const (
+	suggestionsUpstream = "upstream"
+	suggestionsLocal    = "local"
+)
+
+// Validate checks options that depend on each other
+func (c *Config) Validate() error {
+	if c.WriteTimeout <= c.UpstreamTimeout {
+		return fmt.Errorf("%[1]sWRITE_TIMEOUT must be longer than %[1]sUPSTREAM_TIMEOUT", config.EnvPrefix)
+	}
+	if c.MetricsListen != "" && c.MetricsListen == c.Listen {
+		return fmt.Errorf("%[1]sMETRICS_LISTEN must differ from %[1]sLISTEN", config.EnvPrefix)
+	}
+	if c.Suggestions != suggestionsUpstream && c.Suggestions != suggestionsLocal {
+		return fmt.Errorf("%sSUGGESTIONS must be upstream or local", config.EnvPrefix)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rpeshkov/slovnik"
+)
+
+var errNotServing = errors.New("server is not serving")
+
+// probe checks that a dependency of the server works
+type probe func(ctx context.Context) error
+
+// translatorProbe translates the word to check that the dictionary is available and its
+// pages are still parsed
+func translatorProbe(translator slovnik.Translator, word string) probe {
+	return func(ctx context.Context) error {
+		words, err := translator.Translate(ctx, word, slovnik.DetectLanguage(word))
+		if err != nil {
+			return err
+		}
+		if len(words) == 0 {
+			return errors.New("probe word is not found")
+		}
+		return nil
+	}
+}
+
+// readiness reports whether the server may receive requests. Server isn't ready before it
+// starts listening and after shutdown begins. When probe is set, its result is a part of
+// readiness as well. Result of the probe is kept for ttl, so frequent checks don't load
+// the upstream, and the probe is limited by timeout.
+type readiness struct {
+	serving int32
+	probe   probe
+	ttl     time.Duration
+	timeout time.Duration
+
+	mu      sync.Mutex
+	checked time.Time
+	err     error
+
+	// probing is closed when the running probe finishes, it's nil when probe isn't running
+	probing chan struct{}
+}
+
+func newReadiness(p probe, ttl time.Duration, timeout time.Duration) *readiness {
+	return &readiness{probe: p, ttl: ttl, timeout: timeout}
+}
+
+// SetServing changes serving state of the server
+func (r *readiness) SetServing(serving bool) {
+	var v int32
+	if serving {
+		v = 1
+	}
+	atomic.StoreInt32(&r.serving, v)
+}
+
+// Check returns the reason why the server isn't ready. Only one probe runs at a time,
+// other checks get the previous result meanwhile or wait for the first one.
+func (r *readiness) Check(ctx context.Context) error {
+	if atomic.LoadInt32(&r.serving) == 0 {
+		return errNotServing
+	}
+	if r.probe == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	if !r.checked.IsZero() && (time.Since(r.checked) < r.ttl || r.probing != nil) {
+		err := r.err
+		r.mu.Unlock()
+		return err
+	}
+	if done := r.probing; done != nil {
+		r.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return r.err
+	}
+	done := make(chan struct{})
+	r.probing = done
+	r.mu.Unlock()
+
+	// Probe isn't cancelled with the request, otherwise impatient checker would make the
+	// server unready until the result expires
+	probeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.timeout)
+	err := r.probe(probeCtx)
+	cancel()
+	if err != nil {
+		slog.Warn("readiness probe failed", "err", err)
+	}
+
+	r.mu.Lock()
+	r.err, r.checked, r.probing = err, time.Now(), nil
+	r.mu.Unlock()
+	close(done)
+	return err
+}
+
+func (r *readiness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if err := r.Check(req.Context()); err != nil {
+		http.Error(w, "not ready: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ok\n"))
+}
+
+// healthHandler reports that the process is alive
+func healthHandler(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok\n"))
+}
+
+// newServeMux serves health checks besides the API. Health checks aren't logged, as they
+// are made every few seconds.
+func newServeMux(api http.Handler, ready *readiness) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", healthHandler)
+	mux.Handle("/readyz", ready)
+	mux.Handle("/", api)
+	return mux
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rpeshkov/slovnik/phrases"
+)
+
+func TestReadiness(t *testing.T) {
+	calls := 0
+	var probeErr error
+	ready := newReadiness(func(ctx context.Context) error {
+		calls++
+		return probeErr
+	}, time.Hour, time.Second)
+
+	if err := ready.Check(context.Background()); err != errNotServing {
+		t.Errorf("Check() before serving == %v, want %v", err, errNotServing)
+	}
+	if calls != 0 {
+		t.Errorf("probe is called %d times before serving, want 0", calls)
+	}
+
+	ready.SetServing(true)
+	for i := 0; i < 3; i++ {
+		if err := ready.Check(context.Background()); err != nil {
+			t.Errorf("Check() == %v, want nil", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("probe is called %d times, want result to be cached", calls)
+	}
+
+	probeErr = errors.New("upstream is down")
+	ready.checked = ready.checked.Add(-2 * time.Hour)
+	if err := ready.Check(context.Background()); err != probeErr {
+		t.Errorf("Check() after expiration == %v, want %v", err, probeErr)
+	}
+
+	ready.SetServing(false)
+	if err := ready.Check(context.Background()); err != errNotServing {
+		t.Errorf("Check() after shutdown == %v, want %v", err, errNotServing)
+	}
+}
+
+func TestReadinessProbeTimeout(t *testing.T) {
+	release := make(chan struct{})
+	ready := newReadiness(func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-release:
+			return nil
+		}
+	}, 0, 50*time.Millisecond)
+	ready.SetServing(true)
+
+	// Probe is limited even if the request isn't
+	if err := ready.Check(context.Background()); err != context.DeadlineExceeded {
+		t.Fatalf("Check() with hanging probe == %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// Checks made while the probe runs don't wait for it and get the previous result
+	ready.timeout = time.Hour
+	probed := make(chan error, 1)
+	go func() { probed <- ready.Check(context.Background()) }()
+	for probing := false; !probing; {
+		ready.mu.Lock()
+		probing = ready.probing != nil
+		ready.mu.Unlock()
+	}
+
+	if err := ready.Check(context.Background()); err != context.DeadlineExceeded {
+		t.Errorf("Check() while probing == %v, want previous result", err)
+	}
+
+	close(release)
+	if err := <-probed; err != nil {
+		t.Errorf("Check() == %v, want nil", err)
+	}
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	ready := newReadiness(translatorProbe(dictionary{"slovo": {{Word: "slovo"}}}, "slovo"), time.Minute, time.Second)
+	mux := newServeMux(api, ready)
+
+	get := func(path string) int {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	if code := get("/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("GET /readyz before serving == %d, want 503", code)
+	}
+	ready.SetServing(true)
+
+	cases := []struct {
+		path   string
+		status int
+	}{
+		{"/healthz", http.StatusOK},
+		{"/readyz", http.StatusOK},
+		{"/api/v1/translate", http.StatusTeapot},
+	}
+	for _, c := range cases {
+		if code := get(c.path); code != c.status {
+			t.Errorf("GET %s == %d, want %d", c.path, code, c.status)
+		}
+	}
+
+	unknown := newReadiness(translatorProbe(dictionary{}, "slovo"), time.Minute, time.Second)
+	unknown.SetServing(true)
+	if err := unknown.Check(context.Background()); err == nil {
+		t.Error("Check() with unknown probe word == nil, want error")
+	}
+}
+
+func TestMetricsServer(t *testing.T) {
+	get := func(h http.Handler) int {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		return rec.Code
+	}
+
+	if code := get(newMetricsServer(slog.Default()).Handler); code != http.StatusOK {
+		t.Errorf("GET /metrics of metrics server == %d, want 200", code)
+	}
+
+	// Metrics aren't exposed along with the API
+	d := dictionary{}
+	if code := get(newRouter(d, d, phrases.NewIndex(0), time.Hour, nil)); code != http.StatusNotFound {
+		t.Errorf("GET /metrics of API == %d, want 404", code)
+	}
+}
+
+func TestServeDrainsRequests(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("done"))
+	})}
+	ready := newReadiness(nil, time.Minute, time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, slog.Default(), server, l, ready, 0, time.Second)
+	}()
+
+	response := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + l.Addr().String())
+		if err != nil {
+			response <- 0
+			return
+		}
+		resp.Body.Close()
+		response <- resp.StatusCode
+	}()
+
+	<-started
+	cancel()
+
+	if code := <-response; code != http.StatusOK {
+		t.Errorf("in-flight request status == %d, want 200", code)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("serve() == %v, want nil", err)
+	}
+	if err := ready.Check(context.Background()); err != errNotServing {
+		t.Errorf("Check() after shutdown == %v, want %v", err, errNotServing)
+	}
+}
+
+func TestServeShutdownDelay(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ready := newReadiness(nil, time.Minute, time.Second)
+	server := &http.Server{Handler: newServeMux(http.NotFoundHandler(), ready)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, slog.Default(), server, l, ready, 200*time.Millisecond, time.Second)
+	}()
+	for ready.Check(context.Background()) != nil {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	for ready.Check(context.Background()) == nil {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The server is unready, but requests are still accepted during the delay
+	resp, err := http.Get("http://" + l.Addr().String() + "/readyz")
+	if err != nil {
+		t.Fatalf("GET /readyz during shutdown delay failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("GET /readyz during shutdown delay == %d, want 503", resp.StatusCode)
+	}
+
+	if err := <-served; err != nil {
+		t.Errorf("serve() == %v, want nil", err)
+	}
+}
+
+func TestServeShutdownDelayInterrupted(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ready := newReadiness(nil, time.Minute, time.Second)
+	server := &http.Server{Handler: http.NotFoundHandler()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, slog.Default(), server, l, ready, time.Hour, time.Second)
+	}()
+	for ready.Check(context.Background()) != nil {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	for ready.Check(context.Background()) == nil {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Server fails during the delay, there's no reason to wait for the rest of it
+	server.Close()
+	select {
+	case err := <-served:
+		if err != http.ErrServerClosed {
+			t.Errorf("serve() == %v, want %v", err, http.ErrServerClosed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve() waits for shutdown delay after server failed")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// APIKey identifies a client of the API. Zero limits mean that server defaults apply.
+type APIKey struct {
+	Key        string `yaml:"key"`
+	Name       string `yaml:"name"`
+	RateLimit  int    `yaml:"rate_limit"`
+	DailyQuota int    `yaml:"daily_quota"`
+}
+
+// KeyStore looks up API keys sent by clients
+type KeyStore interface {
+	Lookup(key string) (*APIKey, bool)
+}
+
+// fileKeyStore keeps keys loaded from YAML file in memory
+type fileKeyStore struct {
+	keys map[string]*APIKey
+}
+
+// loadKeyFile reads API keys from YAML file. The file contains a list of items with key,
+// name of the client and optional rate_limit and daily_quota.
+func loadKeyFile(path string) (*fileKeyStore, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read API keys: %v", err)
+	}
+
+	var list []*APIKey
+	if err = yaml.UnmarshalStrict(data, &list); err != nil {
+		return nil, fmt.Errorf("bad API keys file %s: %v", path, err)
+	}
+
+	s := &fileKeyStore{keys: make(map[string]*APIKey)}
+	for i, k := range list {
+		if k.Key == "" {
+			return nil, fmt.Errorf("bad API keys file %s: key #%d is empty", path, i+1)
+		}
+		if _, ok := s.keys[k.Key]; ok {
+			return nil, fmt.Errorf("bad API keys file %s: key of %q is duplicated", path, k.Name)
+		}
+		s.keys[k.Key] = k
+	}
+	return s, nil
+}
+
+func (s *fileKeyStore) Lookup(key string) (*APIKey, bool) {
+	k, ok := s.keys[key]
+	return k, ok
+}
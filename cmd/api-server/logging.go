@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// requestIDHeader carries request ID, it's taken from the request when proxy sets it
+const requestIDHeader = "X-Request-ID"
+
+type loggerKey struct{}
+
+// statusRecorder remembers the status written by handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// newRequestID generates random request ID
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// withLogging assigns ID to every request, puts logger with the ID into request context
+// and logs completed requests
+func withLogging(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(requestIDHeader)
+		if id == "" || len(id) > 64 {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		l := logger.With("request_id", id)
+		rec := &statusRecorder{w, http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), loggerKey{}, l)))
+
+		l.Info("request", "method", r.Method, "path", r.URL.Path, "status", rec.status,
+			"latency", time.Since(start))
+	})
+}
+
+// requestLogger returns logger of the request or default logger outside of withLogging
+func requestLogger(r *http.Request) *slog.Logger {
+	if l, ok := r.Context().Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
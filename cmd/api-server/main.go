@@ -1,54 +1,229 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/rpeshkov/slovnik/seznam"
+	"github.com/rpeshkov/slovnik/config"
+	"github.com/rpeshkov/slovnik/phrases"
+	"github.com/rpeshkov/slovnik/suggest"
 
 	"github.com/gorilla/handlers"
 	"github.com/rpeshkov/slovnik"
 
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func main() {
-	translator := seznam.NewTranslator()
+	cfg := &Config{}
+	err := config.Load("api-server", cfg, os.Args[1:])
+	if err == config.ErrExit {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	logger, err := cfg.Logger(os.Stderr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	shutdownTracing, err := cfg.Tracer("api-server")
+	if err != nil {
+		logger.Error("unable to set up tracing", "err", err)
+		os.Exit(1)
+	}
+
+	translator, err := newTranslator(cfg.Upstream, logger)
+	if err != nil {
+		logger.Error("unable to create translator", "err", err)
+		os.Exit(1)
+	}
+
+	// Phrases of entries are indexed as they're fetched, so they can be searched later
+	index := phrases.NewIndex(cfg.PhrasesLimit)
+	translator = phrases.NewTranslator(translator, index)
+
+	var suggester slovnik.Suggester
+	if cfg.Suggestions == suggestionsLocal {
+		local := suggest.NewTranslator(translator, cfg.MaxSuggestWords)
+		translator, suggester = local, local
+	} else {
+		upstream, err := newSeznam(cfg.Upstream, logger)
+		if err != nil {
+			logger.Error("unable to create suggester", "err", err)
+			os.Exit(1)
+		}
+		suggester = newCachedSuggester(upstream, suggestMaxAge, suggestCacheSize)
+	}
+
+	var access *accessControl
+	if cfg.APIKeys != "" {
+		keys, err := loadKeyFile(cfg.APIKeys)
+		if err != nil {
+			logger.Error("unable to load API keys", "err", err)
+			os.Exit(1)
+		}
+		access = newAccessControl(keys, cfg.RateLimit, cfg.DailyQuota)
+	} else {
+		logger.Warn("API keys are not set, API is open to everyone")
+	}
+
+	router := newRouter(translator, suggester, index, cfg.CacheMaxAge, access)
+
+	cors := handlers.CORS(
+		handlers.AllowedOrigins(cfg.CORSOrigins),
+		handlers.AllowedHeaders([]string{apiKeyHeader, "If-None-Match"}),
+		handlers.ExposedHeaders(append([]string{"ETag"}, rateLimitHeaders...)),
+	)
+
+	var p probe
+	if cfg.ReadyProbeWord != "" {
+		dictionary, err := newSeznam(cfg.Upstream, logger)
+		if err != nil {
+			logger.Error("unable to create readiness probe", "err", err)
+			os.Exit(1)
+		}
+		p = translatorProbe(dictionary, cfg.ReadyProbeWord)
+	}
+	ready := newReadiness(p, cfg.ReadyProbeInterval, cfg.ReadyProbeTimeout)
+
+	server := &http.Server{
+		Addr:         cfg.Listen,
+		Handler:      newServeMux(withLogging(logger, withTracing(cors(router))), ready),
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+		ErrorLog:     slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+	}
+
+	l, err := net.Listen("tcp", cfg.Listen)
+	if err != nil {
+		logger.Error("unable to listen", "addr", cfg.Listen, "err", err)
+		os.Exit(1)
+	}
+
+	// Signals are handled by default once shutdown starts, so the second one ends the server
+	// without waiting for the delay and in-flight requests
+	if cfg.MetricsListen != "" {
+		ml, err := net.Listen("tcp", cfg.MetricsListen)
+		if err != nil {
+			logger.Error("unable to listen", "addr", cfg.MetricsListen, "err", err)
+			os.Exit(1)
+		}
+		metricsServer := newMetricsServer(logger)
+		go func() {
+			if err := metricsServer.Serve(ml); err != http.ErrServerClosed {
+				logger.Error("metrics server failed", "err", err)
+			}
+		}()
+		defer metricsServer.Close()
+		logger.Info("serving metrics", "addr", cfg.MetricsListen)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+
+	logger.Info("listening", "addr", cfg.Listen)
+	err = serve(ctx, logger, server, l, ready, cfg.ShutdownDelay, cfg.ShutdownTimeout)
+
+	if e := shutdownTracing(context.Background()); e != nil {
+		logger.Warn("unable to flush traces", "err", e)
+	}
+
+	if err != nil {
+		logger.Error("server failed", "err", err)
+		os.Exit(1)
+	}
+	logger.Info("server stopped")
+}
 
+// newRouter registers routes of the API. Phrases of translated entries must be added to the
+// index. Translations may be cached for cacheMaxAge. API requests are checked by access
+// control unless it's nil.
+func newRouter(translator slovnik.Translator, suggester slovnik.Suggester, index *phrases.Index, cacheMaxAge time.Duration, access *accessControl) *mux.Router {
 	router := mux.NewRouter().StrictSlash(true)
 
+	// Unversioned route is kept for existing clients, its response is the internal model
 	router.
 		Methods(http.MethodGet).
 		Path("/api/translate").
 		Queries("word", "{word}").
-		HandlerFunc(translate(translator))
+		Handler(access.protect(translate(translator, cacheMaxAge)))
+
+	registerV1(router.PathPrefix("/api/v1").Subrouter(), translator, suggester, index, cacheMaxAge, access)
+	return router
+}
+
+// requestLanguage returns translation direction requested by lang query parameter.
+// Language is detected when client doesn't specify it.
+func requestLanguage(r *http.Request, word string) (slovnik.Language, error) {
+	if code := r.URL.Query().Get("lang"); code != "" {
+		return slovnik.ParseLanguage(code)
+	}
+	return slovnik.DetectLanguage(word), nil
+}
 
-	cors := handlers.CORS()
-	err := http.ListenAndServe(":8080", cors(router))
+// lookup translates the word in a span of the request trace and logs the result
+func lookup(r *http.Request, translator slovnik.Translator, word string, lang slovnik.Language) ([]*slovnik.Word, error) {
+	ctx, span := tracer.Start(r.Context(), "api.translate", trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("word", word), attribute.String("direction", lang.String())))
+	defer span.End()
+
+	logger := requestLogger(r).With("word", word, "direction", lang.String(),
+		"trace_id", span.SpanContext().TraceID().String())
+	start := time.Now()
+	words, err := translator.Translate(ctx, word, lang)
 
 	if err != nil {
-		log.Fatal(err)
+		logger.Error("translation failed", "err", err, "latency", time.Since(start))
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
+	logger.Debug("translated", "results", len(words), "latency", time.Since(start))
+	return words, nil
 }
 
-func translate(translator slovnik.Translator) http.HandlerFunc {
+func translate(translator slovnik.Translator, cacheMaxAge time.Duration) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		word := vars["word"]
 
-		lang := slovnik.DetectLanguage(word)
-		translations, err := translator.Translate(word, lang)
+		lang, err := requestLanguage(r, word)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		translations, err := lookup(r, translator, word, lang)
 
 		if err != nil {
 			fmt.Fprintln(w, err)
 			return
 		}
 
+		if notModified(w, r, wordsETag(translations), resultMaxAge(translations, cacheMaxAge)) {
+			return
+		}
+
 		err = json.NewEncoder(w).Encode(translations)
 
 		if err != nil {
+			requestLogger(r).Warn("unable to write response", "err", err)
 			fmt.Fprintln(w, err)
 		}
 	}
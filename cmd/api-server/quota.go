@@ -0,0 +1,97 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// window counts requests made since its start
+type window struct {
+	start time.Time
+	count int
+}
+
+// advance starts the window over when the time doesn't belong to it
+func (w *window) advance(now time.Time, length time.Duration) {
+	if start := now.Truncate(length); !start.Equal(w.start) {
+		w.start = start
+		w.count = 0
+	}
+}
+
+// remaining returns the number of requests left in the window
+func (w *window) remaining(limit int) int {
+	if w.count >= limit {
+		return 0
+	}
+	return limit - w.count
+}
+
+// usage contains counters of one key
+type usage struct {
+	minute window
+	day    window
+}
+
+// quotaStatus describes limits of the key after the request
+type quotaStatus struct {
+	Allowed bool
+
+	// Limit and Remaining are the number of requests allowed per minute and left in
+	// current minute. Reset is the time when current minute ends.
+	Limit     int
+	Remaining int
+	Reset     time.Time
+
+	// DailyLimit and DailyRemaining are the same for the day, DailyLimit is zero when
+	// daily quota isn't limited
+	DailyLimit     int
+	DailyRemaining int
+	DailyReset     time.Time
+}
+
+// quotas counts requests of API keys in fixed windows of a minute and a day. Days start
+// at midnight UTC.
+type quotas struct {
+	mu    sync.Mutex
+	usage map[string]*usage
+}
+
+func newQuotas() *quotas {
+	return &quotas{usage: make(map[string]*usage)}
+}
+
+// Take counts the request of the key if it's within rate limit and daily quota. Rejected
+// requests aren't counted. Zero daily quota means that it isn't limited.
+func (q *quotas) Take(key string, rateLimit int, dailyQuota int, now time.Time) quotaStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	u, ok := q.usage[key]
+	if !ok {
+		u = &usage{}
+		q.usage[key] = u
+	}
+
+	u.minute.advance(now, time.Minute)
+	u.day.advance(now, 24*time.Hour)
+
+	allowed := u.minute.remaining(rateLimit) > 0 && (dailyQuota == 0 || u.day.remaining(dailyQuota) > 0)
+	if allowed {
+		u.minute.count++
+		u.day.count++
+	}
+
+	s := quotaStatus{
+		Allowed:   allowed,
+		Limit:     rateLimit,
+		Remaining: u.minute.remaining(rateLimit),
+		Reset:     now.Truncate(time.Minute).Add(time.Minute),
+	}
+	if dailyQuota > 0 {
+		s.DailyLimit = dailyQuota
+		s.DailyRemaining = u.day.remaining(dailyQuota)
+		s.DailyReset = now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/rpeshkov/slovnik/metrics"
+)
+
+// newMetricsServer creates server of metrics. It's separate from the API, so metrics don't
+// go through API keys and CORS and aren't exposed along with the API.
+func newMetricsServer(logger *slog.Logger) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	return &http.Server{
+		Handler:     mux,
+		ReadTimeout: 10 * time.Second,
+		ErrorLog:    slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+	}
+}
+
+// serve runs the server on the listener until ctx is done. Server becomes unready first
+// and keeps serving for shutdownDelay, then it stops accepting connections and waits up
+// to shutdownTimeout for in-flight requests. The delay ends early when the server fails.
+func serve(ctx context.Context, logger *slog.Logger, server *http.Server, l net.Listener, ready *readiness, shutdownDelay, shutdownTimeout time.Duration) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.Serve(l)
+	}()
+	ready.SetServing(true)
+
+	select {
+	case err := <-errc:
+		ready.SetServing(false)
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Info("shutting down", "delay", shutdownDelay, "timeout", shutdownTimeout)
+	ready.SetServing(false)
+
+	// Load balancers check readiness periodically, requests keep coming until they notice
+	delay := time.NewTimer(shutdownDelay)
+	defer delay.Stop()
+	select {
+	case err := <-errc:
+		return err
+	case <-delay.C:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	<-errc
+	return nil
+}
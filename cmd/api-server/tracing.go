@@ -0,0 +1,20 @@
+package main
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// tracer creates spans of handled requests
+var tracer = otel.Tracer("github.com/rpeshkov/slovnik/cmd/api-server")
+
+// withTracing continues the trace of the client passed in W3C traceparent header, so spans
+// of the request are children of the client span
+func withTracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
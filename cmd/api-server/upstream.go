@@ -0,0 +1,37 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/rpeshkov/slovnik"
+	"github.com/rpeshkov/slovnik/config"
+	"github.com/rpeshkov/slovnik/lemma"
+	"github.com/rpeshkov/slovnik/metrics"
+	"github.com/rpeshkov/slovnik/seznam"
+)
+
+// newTranslator creates translator that looks up words and their base forms in the dictionary.
+// Metrics of lookups and upstream requests are collected.
+func newTranslator(u config.Upstream, logger *slog.Logger) (slovnik.Translator, error) {
+	s, err := newSeznam(u, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	t := lemma.NewTranslator(s)
+	t.SetLogger(logger)
+	return metrics.NewTranslator(t), nil
+}
+
+// newSeznam creates translator of the portal, metrics of its requests are collected.
+// It asks the dictionary directly, so it also suits health checks and suggestions.
+func newSeznam(u config.Upstream, logger *slog.Logger) (*seznam.Translator, error) {
+	client, err := seznam.NewClientWithURL(&http.Client{Timeout: u.UpstreamTimeout}, u.SeznamURL)
+	if err != nil {
+		return nil, err
+	}
+	client.SetLogger(logger)
+
+	return seznam.NewTranslatorWithClient(metrics.NewClient(client)), nil
+}
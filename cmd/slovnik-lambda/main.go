@@ -1,9 +1,16 @@
 package main
 
 import (
-	"github.com/rpeshkov/slovnik/seznam"
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/rpeshkov/slovnik/config"
 
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
 	"github.com/rpeshkov/slovnik"
 )
 
@@ -13,12 +20,53 @@ type Request struct {
 	Word string `json:"word"`
 }
 
-func translate(request Request) ([]*slovnik.Word, error) {
-	translator := seznam.NewTranslator()
-	lang := slovnik.DetectLanguage(request.Word)
-	return translator.Translate(request.Word, lang)
+// Config represents configuration of lambda function
+type Config struct {
+	config.Upstream
+	config.Logging
+}
+
+func translate(translator slovnik.Translator, logger *slog.Logger) func(context.Context, Request) ([]*slovnik.Word, error) {
+	return func(ctx context.Context, request Request) ([]*slovnik.Word, error) {
+		lang := slovnik.DetectLanguage(request.Word)
+
+		l := logger.With("word", request.Word, "direction", lang.String())
+		if lc, ok := lambdacontext.FromContext(ctx); ok {
+			l = l.With("request_id", lc.AwsRequestID)
+		}
+
+		start := time.Now()
+		words, err := translator.Translate(ctx, request.Word, lang)
+		if err != nil {
+			l.Error("translation failed", "err", err, "latency", time.Since(start))
+			return nil, err
+		}
+
+		l.Info("translated", "results", len(words), "latency", time.Since(start))
+		return words, nil
+	}
 }
 
 func main() {
-	lambda.Start(translate)
+	cfg := &Config{}
+	err := config.Load("slovnik-lambda", cfg, os.Args[1:])
+	if err == config.ErrExit {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	logger, err := cfg.Logger(os.Stderr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	translator, err := newTranslator(cfg.Upstream, logger)
+	if err != nil {
+		logger.Error("unable to create translator", "err", err)
+		os.Exit(1)
+	}
+
+	lambda.Start(translate(translator, logger))
 }
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/rpeshkov/slovnik"
+	"github.com/rpeshkov/slovnik/config"
+	"github.com/rpeshkov/slovnik/lemma"
+	"github.com/rpeshkov/slovnik/seznam"
+)
+
+// newTranslator creates translator that looks up words and their base forms in the dictionary
+func newTranslator(u config.Upstream, logger *slog.Logger) (slovnik.Translator, error) {
+	client, err := seznam.NewClientWithURL(&http.Client{Timeout: u.UpstreamTimeout}, u.SeznamURL)
+	if err != nil {
+		return nil, err
+	}
+	client.SetLogger(logger)
+
+	t := lemma.NewTranslator(seznam.NewTranslatorWithClient(client))
+	t.SetLogger(logger)
+	return t, nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const (
+	// Number of requests chat may send at once before rate limit applies
+	chatBurst = 5
+
+	// Users are told about exceeded limits not more often than once a minute
+	noticesPerMinute = 1
+
+	// Key of the only bucket of global limiter
+	globalKey = 0
+)
+
+// inputError is returned when user input can't be translated. Its text is shown to the user.
+type inputError string
+
+func (e inputError) Error() string {
+	return string(e)
+}
+
+const (
+	errInputEmpty   = inputError("Отправьте слово, которое нужно перевести")
+	errInputTooLong = inputError("Слишком длинный запрос")
+	errInputBinary  = inputError("Запрос содержит неподдерживаемые символы")
+)
+
+// validateInput checks that text looks like words or sentences. Line breaks are allowed,
+// pasted text is split into words anyway.
+func validateInput(text string, maxLength int) error {
+	if strings.TrimSpace(text) == "" {
+		return errInputEmpty
+	}
+
+	if !utf8.ValidString(text) {
+		return errInputBinary
+	}
+
+	for _, r := range text {
+		if (unicode.IsControl(r) && !unicode.IsSpace(r)) || r == utf8.RuneError {
+			return errInputBinary
+		}
+	}
+
+	if utf8.RuneCountInString(text) > maxLength {
+		return errInputTooLong
+	}
+
+	return nil
+}
+
+// updateUserID returns the user who sent the update
+func updateUserID(update tgbotapi.Update) int {
+	switch {
+	case update.Message != nil && update.Message.From != nil:
+		return update.Message.From.ID
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.ID
+	case update.InlineQuery != nil:
+		return update.InlineQuery.From.ID
+	}
+	return 0
+}
+
+// isAdmin reports whether user is listed as an administrator of the bot
+func (bot *Bot) isAdmin(userID int) bool {
+	for _, id := range bot.admins {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// allowUpdate checks block list and rate limits before the update is processed.
+// Administrators aren't limited.
+func (bot *Bot) allowUpdate(update tgbotapi.Update) bool {
+	return !bot.isBlocked(update) && bot.withinLimits(update)
+}
+
+// isBlocked reports whether the update comes from blocked chat, or from blocked user for
+// inline queries. Administrators are never blocked.
+func (bot *Bot) isBlocked(update tgbotapi.Update) bool {
+	if bot.isAdmin(updateUserID(update)) {
+		return false
+	}
+
+	chatID := updateChatID(update)
+	blocked, err := bot.blockList.IsBlocked(chatID)
+	if err != nil {
+		bot.logger.Error("unable to check block list", "chat_id", chatID, "err", err)
+	}
+	return blocked
+}
+
+// withinLimits takes a request from per chat and global rate limits. Inline queries are
+// limited per user. Administrators aren't limited.
+func (bot *Bot) withinLimits(update tgbotapi.Update) bool {
+	if bot.isAdmin(updateUserID(update)) {
+		return true
+	}
+
+	if !bot.chatLimiter.Allow(updateChatID(update)) {
+		bot.notifyLimited(update, "Слишком много запросов, подождите немного 🙂")
+		return false
+	}
+
+	if !bot.globalLimiter.Allow(globalKey) {
+		bot.notifyLimited(update, "Бот сейчас перегружен, попробуйте чуть позже")
+		return false
+	}
+
+	return true
+}
+
+// notifyLimited tells the user that the update is rejected. Notices are limited per
+// chat and sent in background, so the receiving of updates isn't slowed down.
+func (bot *Bot) notifyLimited(update tgbotapi.Update, text string) {
+	if update.Message == nil && update.CallbackQuery == nil {
+		return
+	}
+	if !bot.notices.Allow(updateChatID(update)) {
+		return
+	}
+
+	bot.handlers.Add(1)
+	go func() {
+		defer bot.handlers.Done()
+		if update.CallbackQuery != nil {
+			bot.answerCallback(update.CallbackQuery.ID, text)
+		} else {
+			bot.respondError(update.Message.Chat.ID, text)
+		}
+	}()
+}
+
+// handleBlock blocks or unblocks the chat passed as command argument
+func (bot *Bot) handleBlock(message *tgbotapi.Message, block bool) {
+	chatID := message.Chat.ID
+
+	if !bot.isAdmin(message.From.ID) {
+		bot.respondError(chatID, "Команда доступна только администраторам")
+		return
+	}
+
+	target, err := strconv.ParseInt(strings.TrimSpace(message.CommandArguments()), 10, 64)
+	if err != nil {
+		bot.respondError(chatID, "Укажите ID чата: /"+message.Command()+" 123456")
+		return
+	}
+
+	if block {
+		err = bot.blockList.Block(target)
+	} else {
+		err = bot.blockList.Unblock(target)
+	}
+	if err != nil {
+		bot.respondError(chatID, "Something bad happened :(")
+		bot.logger.Error("unable to change block list", "chat_id", chatID, "target", target, "err", err)
+		return
+	}
+
+	if block {
+		bot.respond(chatID, "Чат "+strconv.FormatInt(target, 10)+" заблокирован")
+	} else {
+		bot.respond(chatID, "Чат "+strconv.FormatInt(target, 10)+" разблокирован")
+	}
+}
+
+// handleBlocked sends the list of blocked chats
+func (bot *Bot) handleBlocked(ctx context.Context, message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	if !bot.isAdmin(message.From.ID) {
+		bot.respondError(chatID, "Команда доступна только администраторам")
+		return
+	}
+
+	chats, err := bot.blockList.List()
+	if err != nil {
+		bot.respondError(chatID, "Something bad happened :(")
+		bot.logger.Error("unable to list blocked chats", "chat_id", chatID, "err", err)
+		return
+	}
+
+	if len(chats) == 0 {
+		bot.respond(chatID, "Заблокированных чатов нет")
+		return
+	}
+
+	ids := []string{}
+	for _, id := range chats {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	bot.respond(chatID, "Заблокированные чаты:\n"+strings.Join(ids, "\n"))
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestValidateInput(t *testing.T) {
+	cases := []struct {
+		in   string
+		want error
+	}{
+		{"hlavní město", nil},
+		{"столица", nil},
+		{"", errInputEmpty},
+		{"hlavní\nměsto", nil},
+		{"Je to\r\nPraha.", nil},
+		{"hlavní\tměsto", nil},
+		{"hlavní\x00", errInputBinary},
+		{"\xff\xfe", errInputBinary},
+		{strings.Repeat("ž", 21), errInputTooLong},
+		{strings.Repeat("ž", 20), nil},
+	}
+
+	for _, c := range cases {
+		got := validateInput(c.in, 20)
+		if got != c.want {
+			t.Errorf("validateInput(%q) == %v, want %v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(1, 3)
+
+	for i := 0; i < 3; i++ {
+		if !l.Allow(1) {
+			t.Fatalf("Allow #%d == false, want true", i)
+		}
+	}
+
+	if l.Allow(1) {
+		t.Errorf("Allow after burst == true, want false")
+	}
+
+	if !l.Allow(2) {
+		t.Errorf("Allow for another key == false, want true")
+	}
+}
+
+func TestInlineQueryLimits(t *testing.T) {
+	translator := stubTranslator{"pes": {{Word: "pes", Translations: []string{"собака"}}}}
+	inline := func(id string, userID int) tgbotapi.Update {
+		return tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{
+			ID:    id,
+			From:  &tgbotapi.User{ID: userID},
+			Query: "pes",
+		}}
+	}
+
+	t.Run("blocked", func(t *testing.T) {
+		bot, telegram := newTestBot(t, translator)
+		if err := bot.blockList.Block(7); err != nil {
+			t.Fatal(err)
+		}
+
+		bot.dispatch(inline("1", 7))
+		bot.dispatch(inline("2", 8))
+		bot.handlers.Wait()
+
+		calls := telegram.Calls("answerInlineQuery")
+		if len(calls) != 1 || calls[0].Get("inline_query_id") != "2" {
+			t.Errorf("answered inline queries == %v, want only the query of not blocked user", calls)
+		}
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		bot, telegram := newTestBot(t, translator)
+		bot.chatLimiter = newRateLimiter(1, 1)
+
+		for _, id := range []string{"1", "2"} {
+			bot.dispatch(inline(id, 7))
+			bot.handlers.Wait()
+		}
+
+		if calls := telegram.Calls("answerInlineQuery"); len(calls) != 1 {
+			t.Errorf("answerInlineQuery is called %d times, want 1", len(calls))
+		}
+	})
+}
+
+func TestBlockList(t *testing.T) {
+	bot, _ := newTestBot(t, stubTranslator{})
+	list := bot.blockList
+
+	if err := list.Block(7); err != nil {
+		t.Fatal(err)
+	}
+	if blocked, err := list.IsBlocked(7); err != nil || !blocked {
+		t.Errorf("IsBlocked(7) == %v, %v, want true", blocked, err)
+	}
+
+	// The list is loaded by the first check and then kept in sync
+	if err := list.Unblock(7); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Block(8); err != nil {
+		t.Fatal(err)
+	}
+
+	for chatID, want := range map[int64]bool{7: false, 8: true, 9: false} {
+		if blocked, err := list.IsBlocked(chatID); err != nil || blocked != want {
+			t.Errorf("IsBlocked(%d) == %v, %v, want %v", chatID, blocked, err, want)
+		}
+	}
+}
+
+func TestLimitedNotices(t *testing.T) {
+	bot, telegram := newTestBot(t, stubTranslator{})
+	bot.chatLimiter = newRateLimiter(1, 1)
+
+	callback := func(id string) tgbotapi.Update {
+		return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+			ID:   id,
+			From: &tgbotapi.User{ID: 7},
+			Data: "unknown",
+		}}
+	}
+
+	for _, id := range []string{"1", "2", "3"} {
+		bot.allowUpdate(callback(id))
+	}
+	bot.handlers.Wait()
+
+	// The first callback is allowed, only one of the rejected ones is answered
+	calls := telegram.Calls("answerCallbackQuery")
+	if len(calls) != 1 || calls[0].Get("callback_query_id") != "2" {
+		t.Errorf("answered callbacks == %v, want only callback 2", calls)
+	}
+}
@@ -1,61 +1,110 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"path"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rpeshkov/slovnik"
+	"github.com/rpeshkov/slovnik/metrics"
+	"github.com/rpeshkov/slovnik/speech"
 
 	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
 
 	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracer creates spans of update handling and requests to API server
+var tracer = otel.Tracer("github.com/rpeshkov/slovnik/cmd/telegram-bot")
+
+// apiKeyHeader carries API key of the bot in requests to API server
+const apiKeyHeader = "X-API-Key"
+
 type slovnikClient struct {
 	client  *http.Client
 	baseURL *url.URL
+	apiKey  string
+	logger  *slog.Logger
 }
 
-// newSlovnikClient creates new client for accessing slovnik web server
-func newSlovnikClient(baseURL string, httpClient *http.Client) (*slovnikClient, error) {
+// newSlovnikClient creates new client for accessing slovnik web server. API key is sent
+// with every request unless it's empty.
+func newSlovnikClient(baseURL string, apiKey string, httpClient *http.Client, logger *slog.Logger) (*slovnikClient, error) {
 	var c *http.Client
 
 	if httpClient == nil {
 		c = &http.Client{Timeout: 10 * time.Second}
 	} else {
-		c = http.DefaultClient
+		c = httpClient
 	}
 
 	u, err := url.Parse(baseURL)
 	if err != nil {
 		return nil, err
 	}
-	return &slovnikClient{c, u}, nil
+	return &slovnikClient{c, u, apiKey, logger}, nil
 }
 
-// Translate word
-func (c *slovnikClient) Translate(word string, language slovnik.Language) ([]*slovnik.Word, error) {
+// Translate word. Trace context is passed to API server in traceparent header.
+func (c *slovnikClient) Translate(ctx context.Context, word string, language slovnik.Language) ([]*slovnik.Word, error) {
+	ctx, span := tracer.Start(ctx, "slovnik.Translate", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("word", word), attribute.String("direction", language.String())))
+	defer span.End()
+
 	const methodURL = "/translate"
 	u := *c.baseURL
 	u.Path = path.Join(u.Path, methodURL)
 	q := u.Query()
 	q.Add("word", word)
+	q.Add("lang", language.String())
 	u.RawQuery = q.Encode()
 
-	log.Println(u.String())
-	r, err := c.client.Get(u.String())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if c.apiKey != "" {
+		req.Header.Set(apiKeyHeader, c.apiKey)
+	}
+
+	start := time.Now()
+	r, err := c.client.Do(req)
+	if err != nil {
+		c.logger.Warn("api request failed", "word", word, "direction", language.String(),
+			"latency", time.Since(start), "err", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
 	defer r.Body.Close()
 
+	span.SetAttributes(attribute.Int("http.status_code", r.StatusCode))
+
+	c.logger.Debug("api request", "word", word, "direction", language.String(),
+		"status", r.StatusCode, "latency", time.Since(start))
+
+	if r.StatusCode == http.StatusUnauthorized {
+		span.SetStatus(codes.Error, "unauthorized")
+		return nil, errors.New("API server rejected API key of the bot")
+	}
+
 	if r.StatusCode != http.StatusOK {
+		span.SetStatus(codes.Error, "bad status")
 		return nil, fmt.Errorf("Got bad status (%d) from server", r.StatusCode)
 	}
 
@@ -64,6 +113,12 @@ func (c *slovnikClient) Translate(word string, language slovnik.Language) ([]*sl
 	return w, nil
 }
 
+// Number of suggested words in one keyboard row
+const suggestionsPerRow = 2
+
+// Number of words that can be referenced by inline buttons at the same time
+const callbackTokensLimit = 10000
+
 // Bot type aggregate all bot logic
 type Bot struct {
 	api *tgbotapi.BotAPI
@@ -74,35 +129,112 @@ type Bot struct {
 	templates *Template
 
 	translator slovnik.Translator
+
+	logger *slog.Logger
+
+	// tokens resolves words referenced by inline buttons
+	tokens *callbackTokens
+
+	// phrasesPageSize is used for chats that didn't change it in settings
+	phrasesPageSize int
+
+	inlineDebouncer *debouncer
+	inlineCache     *translationCache
+
+	// inlineSlots limits the number of inline queries translated at the same time
+	inlineSlots chan struct{}
+
+	db         *bolt.DB
+	vocabulary VocabularyStore
+	quizzes    *quizSessions
+	settings   SettingsStore
+
+	router *commandRouter
+
+	admins         []int
+	blockList      BlockList
+	chatLimiter    *rateLimiter
+	globalLimiter  *rateLimiter
+	notices        *rateLimiter
+	maxInputLength int
+
+	// recognizer converts voice messages to text, it's nil when voice messages aren't supported
+	recognizer slovnik.SpeechRecognizer
+
+	// synthesizer pronounces words, it's nil when pronunciation isn't supported
+	synthesizer slovnik.SpeechSynthesizer
+	voices      VoiceStore
+
+	// maxTokens limits the number of words looked up for one message
+	maxTokens int
+
+	// subscriptions keeps chats that receive word of the day
+	subscriptions SubscriptionStore
+	dailyWords    []string
+
+	// timezone is used for subscriptions that don't specify it
+	timezone string
+
+	// server serves health checks and metrics, in webhook mode it receives updates as well
+	server   *http.Server
+	webhook  bool
+	certFile string
+	keyFile  string
+
+	// ready is set while updates are processed, it's reported by /readyz
+	ready *int32
+
+	// dispatcher processes updates concurrently keeping the order within each chat
+	dispatcher *dispatcher
+
+	// handlers tracks updates that are processed outside of dispatcher
+	handlers sync.WaitGroup
+
+	shutdownTimeout time.Duration
+
+	// lastUpdateID is the latest dispatched update, it's accessed only by receiving loop
+	lastUpdateID int
 }
 
 // NewBot creates and initializes new bot
-func NewBot(config *Config, templates *Template) (*Bot, error) {
+func NewBot(config *Config, templates *Template, logger *slog.Logger) (*Bot, error) {
 	botAPI, err := tgbotapi.NewBotAPI(config.BotID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to init bot")
 	}
 
-	slovnikClient, err := newSlovnikClient(config.SlovnikURL, nil)
+	slovnikClient, err := newSlovnikClient(config.SlovnikURL, config.APIKey, &http.Client{Timeout: config.APITimeout}, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to init slovnikClient")
 	}
 
+	dailyWords, err := loadWordList(config.DailyWords)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := openDatabase(config.DBPath)
+	if err != nil {
+		return nil, err
+	}
+
 	var updates tgbotapi.UpdatesChannel
+	var ready int32
+	mux := newServeMux(&ready)
 
 	if config.IsWebhook() {
-		log.Printf("WebhookHost is set. URL: %s. Using webhooks\n", config.WebhookURL)
+		logger.Info("using webhook", "url", config.WebhookURL)
 
-		webHook := tgbotapi.NewWebhook(config.WebhookURL)
-		if _, err = botAPI.SetWebhook(webHook); err != nil {
-			return nil, errors.Wrap(err, "webhook set failed")
+		if err = setWebhook(botAPI, config); err != nil {
+			return nil, err
 		}
 
-		updates = botAPI.ListenForWebhook("/bot" + botAPI.Token)
+		ch := make(chan tgbotapi.Update, botAPI.Buffer)
+		updates = ch
 
-		go http.ListenAndServe("0.0.0.0:8080", nil)
+		mux.Handle(config.WebhookPath, webhookHandler(config.WebhookSecret, ch))
 	} else {
-		log.Println("WebhookURL environment variable is not set. Using polling.")
+		logger.Info("webhook host is not set, using polling")
 
 		u := tgbotapi.NewUpdate(0)
 		u.Timeout = 60
@@ -113,104 +245,551 @@ func NewBot(config *Config, templates *Template) (*Bot, error) {
 		}
 	}
 
-	return &Bot{botAPI, updates, templates, slovnikClient}, nil
+	bot := &Bot{
+		api:             botAPI,
+		updates:         updates,
+		templates:       templates,
+		translator:      metrics.NewTranslator(slovnikClient),
+		logger:          logger,
+		tokens:          newCallbackTokens(db, callbackTokensLimit, logger),
+		phrasesPageSize: config.PhrasesPageSize,
+		inlineDebouncer: newDebouncer(inlineDebounce),
+		inlineCache:     newTranslationCache(inlineCacheTTL),
+		inlineSlots:     make(chan struct{}, config.Workers),
+		db:              db,
+		vocabulary:      newBoltVocabulary(db),
+		quizzes:         newQuizSessions(),
+		settings:        newBoltSettings(db),
+		voices:          newBoltVoices(db),
+		admins:          config.Admins,
+		blockList:       newBoltBlockList(db),
+		chatLimiter:     newRateLimiter(config.ChatRateLimit, chatBurst),
+		globalLimiter:   newRateLimiter(config.GlobalRateLimit, config.GlobalRateLimit/6+1),
+		notices:         newRateLimiter(noticesPerMinute, 1),
+		maxInputLength:  config.MaxInputLength,
+		maxTokens:       config.MaxTokens,
+		subscriptions:   newBoltSubscriptions(db),
+		dailyWords:      dailyWords,
+		timezone:        config.Timezone,
+		server:          &http.Server{Addr: config.WebhookListen, Handler: mux},
+		webhook:         config.IsWebhook(),
+		certFile:        config.WebhookCert,
+		keyFile:         config.WebhookKey,
+		ready:           &ready,
+		shutdownTimeout: config.ShutdownTimeout,
+	}
+	if config.WhisperModel != "" {
+		recognizer := speech.NewWhisperRecognizer(config.WhisperModel)
+		recognizer.Timeout = config.SpeechTimeout
+		bot.recognizer = recognizer
+	}
+
+	if config.EspeakBinary != "" {
+		synthesizer := speech.NewEspeakSynthesizer(config.EspeakBinary)
+		synthesizer.Timeout = config.SpeechTimeout
+		bot.synthesizer = synthesizer
+	}
+
+	bot.dispatcher = newDispatcher(config.Workers, config.QueueSize, bot.handleUpdate)
+	bot.router = newBotRouter(bot)
+
+	// Bot works without commands in menu, so failure isn't fatal
+	if err = bot.registerCommands(); err != nil {
+		logger.Warn("unable to register commands", "err", err)
+	}
+
+	return bot, nil
+}
+
+// Listen start listening on message updates and calling provided handler for processing incoming messages.
+// Listening stops when ctx is cancelled or HTTP server fails, after that the bot is shut down.
+func (bot *Bot) Listen(ctx context.Context) error {
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := bot.serve(); err != http.ErrServerClosed {
+			serverErr <- err
+		}
+	}()
+
+	// Word of the day is sent until the bot starts shutting down
+	dailyCtx, stopDaily := context.WithCancel(ctx)
+	bot.handlers.Add(1)
+	go func() {
+		defer bot.handlers.Done()
+		bot.runDaily(dailyCtx)
+	}()
+
+	atomic.StoreInt32(bot.ready, 1)
+
+	var err error
+	for err == nil {
+		select {
+		case <-ctx.Done():
+			bot.logger.Info("shutting down")
+			stopDaily()
+			return bot.shutdown()
+		case e := <-serverErr:
+			err = errors.Wrap(e, "HTTP server failed")
+		case update := <-bot.updates:
+			bot.dispatch(update)
+		}
+	}
+
+	stopDaily()
+	if shutdownErr := bot.shutdown(); shutdownErr != nil {
+		bot.logger.Error("shutdown failed", "err", shutdownErr)
+	}
+	return err
 }
 
-// Listen start listening on message updates and calling provided handler for processing incoming messages
-func (bot *Bot) Listen() {
-	for update := range bot.updates {
-		if update.Message != nil {
-			bot.handleMessage(&update)
-		} else if update.CallbackQuery != nil {
-			bot.handleCallbackQuery(&update)
+// shutdown stops receiving new updates, processes the ones that are already received and waits
+// for background handlers. Resources are released even if shutdown timeout is exceeded.
+func (bot *Bot) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), bot.shutdownTimeout)
+	defer cancel()
+	defer bot.db.Close()
+
+	atomic.StoreInt32(bot.ready, 0)
+
+	if !bot.webhook {
+		bot.api.StopReceivingUpdates()
+	}
+
+	// Webhook handler blocks until update is taken from the channel, so updates are
+	// consumed while server is waiting for active requests
+	done := make(chan error, 1)
+	go func() { done <- bot.server.Shutdown(ctx) }()
+
+	for stopped := false; !stopped; {
+		select {
+		case update := <-bot.updates:
+			bot.dispatch(update)
+		case err := <-done:
+			if err != nil {
+				bot.logger.Error("HTTP server shutdown failed", "err", err)
+			}
+			stopped = true
+		}
+	}
+
+	// Updates that are already received are processed. Telegram confirms polled updates
+	// only by the next poll, so they're confirmed explicitly below.
+	for pending := true; pending; {
+		select {
+		case update := <-bot.updates:
+			bot.dispatch(update)
+		default:
+			pending = false
 		}
 	}
+
+	if !bot.webhook {
+		bot.confirmUpdates()
+	}
+
+	bot.dispatcher.Close()
+
+	finished := make(chan struct{})
+	go func() {
+		bot.dispatcher.Wait()
+		bot.handlers.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return errors.New("timed out waiting for update handlers")
+	}
 }
 
-func (bot *Bot) handleMessage(update *tgbotapi.Update) {
-	words, err := bot.translator.Translate(update.Message.Text, slovnik.Cz)
+// confirmUpdates tells telegram that all dispatched updates are received, otherwise the last
+// polled batch is delivered again after restart. The request also ends the long poll that
+// may still be in flight, updates it has received aren't confirmed and will be delivered again.
+func (bot *Bot) confirmUpdates() {
+	if bot.lastUpdateID == 0 {
+		return
+	}
+
+	_, err := bot.api.GetUpdates(tgbotapi.UpdateConfig{Offset: bot.lastUpdateID + 1, Limit: 1})
 	if err != nil {
-		bot.respondError(update.Message.Chat.ID, "Something bad happened :(")
-		log.Println(err)
+		bot.logger.Warn("unable to confirm updates, they may be processed again after restart",
+			"update_id", bot.lastUpdateID, "err", err)
+	}
+}
+
+// dispatch passes update for processing. It blocks when too many updates are waiting.
+// Updates from blocked chats and updates exceeding rate limits are dropped.
+func (bot *Bot) dispatch(update tgbotapi.Update) {
+	metrics.BotUpdate(updateType(update))
+	if update.UpdateID > bot.lastUpdateID {
+		bot.lastUpdateID = update.UpdateID
+	}
+
+	// Group messages that aren't addressed to the bot are ignored before they count
+	// against rate limits
+	if update.Message != nil {
+		if _, ok := groupRequest(update.Message, bot.api.Self); !ok {
+			return
+		}
+	}
+
+	if update.InlineQuery == nil {
+		if bot.allowUpdate(update) {
+			bot.dispatcher.Dispatch(update)
+		}
 		return
 	}
 
-	messageText := bot.templates.Translation(words)
+	// Rate limits of inline queries are checked after debouncing, so that typing isn't
+	// counted, but blocked users don't get even that far
+	if bot.isBlocked(update) {
+		return
+	}
+
+	// Inline queries are debounced, the newer query of the user supersedes the one that is
+	// waiting, so they can't be queued one after another
+	bot.handlers.Add(1)
+	replaced := bot.inlineDebouncer.Debounce(update.InlineQuery.From.ID, func() {
+		defer bot.handlers.Done()
+		bot.handleInline(update)
+	})
+	if replaced {
+		bot.handlers.Done()
+	}
+}
 
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID, messageText)
+// handleInline processes inline query if there's a free slot. Queries aren't queued,
+// results would be late anyway, the user gets the answer to the next query.
+func (bot *Bot) handleInline(update tgbotapi.Update) {
+	select {
+	case bot.inlineSlots <- struct{}{}:
+		defer func() { <-bot.inlineSlots }()
+	default:
+		bot.logger.Warn("inline query dropped, all slots are busy", "user_id", update.InlineQuery.From.ID)
+		return
+	}
+
+	defer recoverUpdate(update)
+	bot.handleUpdate(update)
+}
+
+// handleUpdate calls the handler suitable for the update. Each update starts a new trace
+// that's continued by requests to API server.
+func (bot *Bot) handleUpdate(update tgbotapi.Update) {
+	start := time.Now()
+	ctx, span := tracer.Start(context.Background(), "bot.update", trace.WithAttributes(
+		attribute.Int("update_id", update.UpdateID),
+		attribute.String("type", updateType(update)),
+		attribute.Int64("chat_id", updateChatID(update)),
+	))
+	defer span.End()
+
+	switch {
+	case update.Message != nil:
+		bot.handleMessage(ctx, &update)
+	case update.CallbackQuery != nil:
+		bot.handleCallbackQuery(ctx, &update)
+	case update.InlineQuery != nil:
+		bot.handleInlineQuery(ctx, &update)
+	}
+
+	bot.logger.Debug("update handled", "update_id", update.UpdateID, "chat_id", updateChatID(update),
+		"trace_id", span.SpanContext().TraceID().String(), "latency", time.Since(start))
+}
+
+func (bot *Bot) handleMessage(ctx context.Context, update *tgbotapi.Update) {
+	message := update.Message
+	if message.IsCommand() {
+		bot.router.Route(ctx, message)
+		return
+	}
+
+	if message.Voice != nil {
+		bot.handleVoice(ctx, message)
+		return
+	}
+
+	text, _ := groupRequest(message, bot.api.Self)
+	if err := validateInput(text, bot.maxInputLength); err != nil {
+		bot.respondError(message.Chat.ID, err.Error())
+		return
+	}
+
+	if bot.checkQuizAnswer(message.Chat.ID, text) {
+		return
+	}
+
+	bot.translateText(ctx, message.Chat.ID, text, "")
+}
+
+// translateText sends translation of the text to the chat. Text with several words gets
+// a glossary. Non empty note is shown above the translation.
+func (bot *Bot) translateText(ctx context.Context, chatID int64, text string, note string) {
+	tokens := slovnik.Tokenize(text)
+	if len(tokens) > 1 {
+		bot.handleGlossary(ctx, chatID, tokens, note)
+		return
+	}
+
+	// Punctuation around single word isn't a part of it
+	word := text
+	if len(tokens) == 1 {
+		word = tokens[0]
+	}
+	bot.sendTranslation(ctx, chatID, word, note)
+}
+
+// sendTranslation translates the word and sends the result to the chat
+func (bot *Bot) sendTranslation(ctx context.Context, chatID int64, word string, note string) {
+	settings := bot.chatSettings(chatID)
+	words, err := bot.translator.Translate(ctx, word, settings.Language(word))
+	if err != nil {
+		bot.respondError(chatID, "Something bad happened :(")
+		bot.logger.Error("translation failed", "chat_id", chatID, "word", word, "err", err)
+		return
+	}
+
+	messageText := note + bot.templates.Translation(words)
+
+	msg := tgbotapi.NewMessage(chatID, messageText)
 	msg.ParseMode = tgbotapi.ModeMarkdown
 
-	hasPhrases := len(words) == 1 && len(words[0].Samples) > 0
-	if hasPhrases {
-		keyboard := bot.addMessageKeyboard(words)
-		if keyboard != nil {
-			msg.ReplyMarkup = keyboard
-		}
+	if keyboard := bot.messageKeyboard(words, word, settings); keyboard != nil {
+		msg.ReplyMarkup = keyboard
 	}
 
 	_, err = bot.api.Send(msg)
 
 	if err != nil {
-		log.Println(err)
+		bot.logger.Error("unable to send translation", "chat_id", chatID, "word", word, "err", err)
+	}
+}
+
+// answerCallback notifies telegram that callback query is processed. Non empty text is shown
+// to the user as a notification.
+func (bot *Bot) answerCallback(queryID string, text string) {
+	_, err := bot.api.AnswerCallbackQuery(tgbotapi.NewCallback(queryID, text))
+	if err != nil {
+		bot.logger.Error("unable to answer callback", "query_id", queryID, "err", err)
 	}
 }
 
 // respondError writes an error to the chat
 func (bot *Bot) respondError(updateID int64, text string) {
-	msg := tgbotapi.NewMessage(updateID, text)
+	bot.respond(updateID, text)
+}
+
+// respond writes plain text message to the chat
+func (bot *Bot) respond(chatID int64, text string) {
+	msg := tgbotapi.NewMessage(chatID, text)
 	_, err := bot.api.Send(msg)
 	if err != nil {
-		log.Println(err)
+		bot.logger.Error("unable to send message", "chat_id", chatID, "err", err)
 	}
 }
 
-func (bot *Bot) handleCallbackQuery(update *tgbotapi.Update) {
-	callbackData := update.CallbackQuery.Data
-	chatID := update.CallbackQuery.Message.Chat.ID
-	messageID := update.CallbackQuery.Message.MessageID
+func (bot *Bot) handleCallbackQuery(ctx context.Context, update *tgbotapi.Update) {
+	query := update.CallbackQuery
+	callbackData := query.Data
 
-	if strings.HasPrefix(callbackData, "phrases:") {
-		w := strings.TrimPrefix(callbackData, "phrases:")
+	if strings.HasPrefix(callbackData, legacyPhrasesPrefix) {
+		w := strings.TrimPrefix(callbackData, legacyPhrasesPrefix)
+		bot.showPhrases(ctx, query, bot.tokens.Token(w), 0, noKeyword, false)
+		return
+	}
 
-		words, err := bot.translator.Translate(w, slovnik.Cz)
-		if err != nil {
-			bot.respondError(chatID, "Error occured when I tried to get phrases :(")
-			log.Println(err)
-			return
+	data, err := parseCallbackData(callbackData)
+	if err != nil {
+		bot.answerCallback(query.ID, "")
+		bot.logger.Warn("bad callback data", "chat_id", query.Message.Chat.ID, "data", callbackData, "err", err)
+		return
+	}
+
+	switch data.Action {
+	case actionPhrases:
+		// Only navigation buttons carry arguments, they are attached to phrases message itself
+		inPlace := len(data.Args) > 0
+		bot.showPhrases(ctx, query, data.Token, data.Arg(0, 0), data.Arg(1, noKeyword), inPlace)
+	case actionWord:
+		bot.showWord(ctx, query, data.Token)
+	case actionSave:
+		bot.saveWord(ctx, query, data.Token)
+	case actionReveal:
+		bot.revealAnswer(query, data.Token)
+	case actionSettings:
+		bot.changeSetting(query, data)
+	case actionExpand:
+		bot.expandWord(ctx, query, data.Token)
+	case actionSay:
+		bot.sayWord(ctx, query, data.Token)
+	default:
+		bot.answerCallback(query.ID, "")
+	}
+}
+
+// showPhrases shows requested page of phrases. When inPlace is set, the message with the button
+// is edited, otherwise new message is sent and the keyboard is removed from translation message.
+func (bot *Bot) showPhrases(ctx context.Context, query *tgbotapi.CallbackQuery, token string, page int, keywordIdx int, inPlace bool) {
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+
+	w, ok := bot.tokens.Word(token)
+	if !ok {
+		bot.answerCallback(query.ID, "Кнопка устарела, повторите запрос")
+		return
+	}
+
+	settings := bot.chatSettings(chatID)
+	words, err := bot.translator.Translate(ctx, w, settings.Language(w))
+	if err != nil {
+		bot.answerCallback(query.ID, "")
+		bot.respondError(chatID, "Error occured when I tried to get phrases :(")
+		bot.logger.Error("translation failed", "chat_id", chatID, "word", w, "err", err)
+		return
+	}
+
+	if len(words) != 1 {
+		bot.answerCallback(query.ID, "Фразы не найдены")
+		return
+	}
+
+	keywords := sampleKeywords(words[0].Samples)
+	if keywordIdx >= len(keywords) || keywordIdx < noKeyword {
+		keywordIdx = noKeyword
+	}
+
+	keyword := ""
+	if keywordIdx != noKeyword {
+		keyword = keywords[keywordIdx]
+	}
+
+	pageSize := bot.chatSettings(chatID).PhrasesPageSize
+	p := paginatePhrases(words[0], keyword, page, pageSize)
+	messageText := bot.templates.Phrases(p)
+	keyboard := phrasesKeyboard(token, keywords, keywordIdx, p)
+
+	bot.answerCallback(query.ID, "")
+
+	if inPlace {
+		editMsg := tgbotapi.NewEditMessageText(chatID, messageID, messageText)
+		editMsg.ReplyMarkup = keyboard
+		editMsg.ParseMode = tgbotapi.ModeMarkdown
+		if _, err = bot.api.Send(editMsg); err != nil {
+			bot.logger.Error("unable to update phrases", "chat_id", chatID, "word", w, "err", err)
 		}
+		return
+	}
 
-		messageText := bot.templates.Phrases(words)
+	msg := tgbotapi.NewMessage(chatID, messageText)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	if keyboard != nil {
+		msg.ReplyMarkup = keyboard
+	}
 
-		msg := tgbotapi.NewMessage(chatID, messageText)
-		msg.ParseMode = tgbotapi.ModeMarkdown
+	_, err = bot.api.Send(msg)
+	if err != nil {
+		bot.logger.Error("unable to send phrases", "chat_id", chatID, "word", w, "err", err)
+		return
+	}
 
-		_, err = bot.api.Send(msg)
-		if err != nil {
-			log.Println(err)
-			return
+	editMsg := tgbotapi.NewEditMessageText(chatID, messageID, bot.templates.Translation(words))
+	editMsg.ReplyMarkup = nil
+	editMsg.ParseMode = tgbotapi.ModeMarkdown
+	_, err = bot.api.Send(editMsg)
+
+	if err != nil {
+		bot.logger.Error("unable to remove phrases button", "chat_id", chatID, "err", err)
+		return
+	}
+}
+
+// showWord replaces the list of suggestions with full translation of selected word
+func (bot *Bot) showWord(ctx context.Context, query *tgbotapi.CallbackQuery, token string) {
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+
+	w, ok := bot.tokens.Word(token)
+	if !ok {
+		bot.answerCallback(query.ID, "Кнопка устарела, повторите запрос")
+		return
+	}
+
+	settings := bot.chatSettings(chatID)
+	words, err := bot.translator.Translate(ctx, w, settings.Language(w))
+	if err != nil {
+		bot.answerCallback(query.ID, "")
+		bot.respondError(chatID, "Something bad happened :(")
+		bot.logger.Error("translation failed", "chat_id", chatID, "word", w, "err", err)
+		return
+	}
+
+	bot.answerCallback(query.ID, "")
+
+	editMsg := tgbotapi.NewEditMessageText(chatID, messageID, bot.templates.Translation(words))
+	editMsg.ReplyMarkup = bot.messageKeyboard(words, w, settings)
+	editMsg.ParseMode = tgbotapi.ModeMarkdown
+	if _, err = bot.api.Send(editMsg); err != nil {
+		bot.logger.Error("unable to show word", "chat_id", chatID, "word", w, "err", err)
+	}
+}
+
+// messageKeyboard creates keyboard for translation message of the query. Full translation gets
+// the buttons for phrases and for saving the word, mistype suggestions get a button for each
+// suggested word, even if there's only one. Phrases button is omitted when the chat hides it
+// in settings.
+func (bot *Bot) messageKeyboard(words []*slovnik.Word, query string, settings ChatSettings) *tgbotapi.InlineKeyboardMarkup {
+	if !slovnik.IsEntry(words, query) {
+		return bot.suggestionsKeyboard(words)
+	}
+
+	token := bot.tokens.Token(words[0].Word)
+	row := []tgbotapi.InlineKeyboardButton{}
+
+	if len(words[0].Samples) > 0 && !settings.HidePhrases {
+		phrases := callbackData{Action: actionPhrases, Token: token}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("Фразы", phrases.String()))
+	}
+
+	if bot.synthesizer != nil {
+		say := callbackData{Action: actionSay, Token: token}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("🔊", say.String()))
+	}
+
+	save := callbackData{Action: actionSave, Token: token}
+	row = append(row, tgbotapi.NewInlineKeyboardButtonData("⭐ Save", save.String()))
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(row)
+	return &keyboard
+}
+
+// suggestionsKeyboard creates a button for each suggested word
+func (bot *Bot) suggestionsKeyboard(words []*slovnik.Word) *tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	row := []tgbotapi.InlineKeyboardButton{}
+
+	for _, w := range words {
+		if w.Word == "" {
+			continue
 		}
 
-		editMsg := tgbotapi.NewEditMessageText(chatID, messageID, bot.templates.Translation(words))
-		editMsg.ReplyMarkup = nil
-		editMsg.ParseMode = tgbotapi.ModeMarkdown
-		_, err = bot.api.Send(editMsg)
+		data := callbackData{Action: actionWord, Token: bot.tokens.Token(w.Word)}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(buttonText(w.Word), data.String()))
 
-		if err != nil {
-			log.Println(err)
-			return
+		if len(row) == suggestionsPerRow {
+			rows = append(rows, row)
+			row = []tgbotapi.InlineKeyboardButton{}
 		}
 	}
-}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
 
-func (bot *Bot) addMessageKeyboard(words []*slovnik.Word) *tgbotapi.InlineKeyboardMarkup {
-	if words == nil || len(words) > 1 || len(words[0].Samples) <= 0 {
+	if len(rows) == 0 {
 		return nil
 	}
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Фразы", "phrases:"+words[0].Word),
-		),
-	)
 
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
 	return &keyboard
 }
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/rpeshkov/slovnik"
+)
+
+// fakeTelegram answers requests of bot API with successful responses and records them
+type fakeTelegram struct {
+	mu       sync.Mutex
+	requests []telegramRequest
+}
+
+// telegramRequest is a call of bot API method
+type telegramRequest struct {
+	Method string
+	Params url.Values
+}
+
+func (f *fakeTelegram) RoundTrip(r *http.Request) (*http.Response, error) {
+	method := path.Base(r.URL.Path)
+	r.ParseMultipartForm(1 << 20)
+
+	f.mu.Lock()
+	f.requests = append(f.requests, telegramRequest{method, r.Form})
+	f.mu.Unlock()
+
+	result := "true"
+	switch method {
+	case "getMe":
+		result = `{"id":1,"is_bot":true,"first_name":"Slovnik","username":"slovnik_bot"}`
+	case "getUpdates":
+		result = "[]"
+	case "sendMessage", "editMessageText", "sendVoice":
+		result = `{"message_id":1,"chat":{"id":1}}`
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(`{"ok":true,"result":` + result + `}`)),
+		Request:    r,
+	}, nil
+}
+
+// Calls returns parameters of the method calls
+func (f *fakeTelegram) Calls(method string) []url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var calls []url.Values
+	for _, r := range f.requests {
+		if r.Method == method {
+			calls = append(calls, r.Params)
+		}
+	}
+	return calls
+}
+
+// stubTranslator returns words stored for the word, unknown words aren't found
+type stubTranslator map[string][]*slovnik.Word
+
+func (s stubTranslator) Translate(ctx context.Context, word string, language slovnik.Language) ([]*slovnik.Word, error) {
+	return s[word], nil
+}
+
+// newTestBot creates bot that talks to fake telegram and keeps data in temporary database
+func newTestBot(t *testing.T, translator slovnik.Translator) (*Bot, *fakeTelegram) {
+	telegram := &fakeTelegram{}
+	api, err := tgbotapi.NewBotAPIWithClient("token", &http.Client{Transport: telegram})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := openDatabase(filepath.Join(t.TempDir(), "slovnik.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	templates, err := CreateTemplate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bot := &Bot{
+		api:             api,
+		templates:       templates,
+		translator:      translator,
+		logger:          slog.Default(),
+		tokens:          newCallbackTokens(db, callbackTokensLimit, slog.Default()),
+		phrasesPageSize: 5,
+		inlineDebouncer: newDebouncer(0),
+		inlineCache:     newTranslationCache(inlineCacheTTL),
+		inlineSlots:     make(chan struct{}, 4),
+		db:              db,
+		vocabulary:      newBoltVocabulary(db),
+		quizzes:         newQuizSessions(),
+		settings:        newBoltSettings(db),
+		voices:          newBoltVoices(db),
+		blockList:       newBoltBlockList(db),
+		chatLimiter:     newRateLimiter(100, 100),
+		globalLimiter:   newRateLimiter(1000, 1000),
+		notices:         newRateLimiter(noticesPerMinute, 1),
+		maxInputLength:  100,
+		maxTokens:       10,
+		subscriptions:   newBoltSubscriptions(db),
+		timezone:        "Europe/Prague",
+		ready:           new(int32),
+	}
+	bot.router = newBotRouter(bot)
+	return bot, telegram
+}
+
+func TestConfirmUpdates(t *testing.T) {
+	bot, telegram := newTestBot(t, stubTranslator{})
+
+	bot.confirmUpdates()
+	if calls := telegram.Calls("getUpdates"); len(calls) != 0 {
+		t.Errorf("getUpdates is called %d times before any update, want 0", len(calls))
+	}
+
+	bot.lastUpdateID = 41
+	bot.confirmUpdates()
+
+	calls := telegram.Calls("getUpdates")
+	if len(calls) != 1 {
+		t.Fatalf("getUpdates is called %d times, want 1", len(calls))
+	}
+	if offset, timeout := calls[0].Get("offset"), calls[0].Get("timeout"); offset != "42" || timeout != "" {
+		t.Errorf("getUpdates offset == %q, timeout == %q, want offset 42 without timeout", offset, timeout)
+	}
+}
+
+func TestMessageKeyboard(t *testing.T) {
+	bot, _ := newTestBot(t, stubTranslator{})
+	long := strings.Repeat("nejneobhospodařovávatelnější", 2)
+
+	cases := []struct {
+		words    []*slovnik.Word
+		query    string
+		settings ChatSettings
+		want     [][]string
+	}{
+		{nil, "xyz", ChatSettings{}, nil},
+		{
+			[]*slovnik.Word{{Word: "pes", WordType: "m", Samples: []slovnik.SampleUse{{Keyword: "pes", Phrase: "pes štěká"}}}},
+			"pes", ChatSettings{},
+			[][]string{{"Фразы", "⭐ Save"}},
+		},
+		{
+			[]*slovnik.Word{{Word: "pes", WordType: "m", Samples: []slovnik.SampleUse{{Keyword: "pes", Phrase: "pes štěká"}}}},
+			"pes", ChatSettings{HidePhrases: true},
+			[][]string{{"⭐ Save"}},
+		},
+		// Query without diacritics finds the entry itself
+		{[]*slovnik.Word{{Word: "kočka", Translations: []string{"кошка"}}}, "kocka", ChatSettings{}, [][]string{{"⭐ Save"}}},
+		// Single mistype suggestion gets a button like several ones
+		{[]*slovnik.Word{{Word: "kočka", Translations: []string{"кошка"}}}, "kocak", ChatSettings{}, [][]string{{"kočka"}}},
+		{
+			[]*slovnik.Word{{Word: "kočka"}, {Word: long}, {Word: "kočár"}},
+			"koc", ChatSettings{},
+			[][]string{{"kočka", buttonText(long)}, {"kočár"}},
+		},
+	}
+
+	for _, c := range cases {
+		keyboard := bot.messageKeyboard(c.words, c.query, c.settings)
+
+		var got [][]string
+		if keyboard != nil {
+			for _, row := range keyboard.InlineKeyboard {
+				texts := []string{}
+				for _, b := range row {
+					texts = append(texts, b.Text)
+
+					// Long words are shortened only on the button
+					data, err := parseCallbackData(*b.CallbackData)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if w, _ := bot.tokens.Word(data.Token); data.Action == actionWord && buttonText(w) != b.Text {
+						t.Errorf("button %q refers to %q", b.Text, w)
+					}
+				}
+				got = append(got, texts)
+			}
+		}
+
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("messageKeyboard(%q) == %q, want %q", c.query, got, c.want)
+		}
+	}
+
+	if text := buttonText(long); len([]rune(text)) != maxButtonTextLen || !strings.HasSuffix(text, "…") {
+		t.Errorf("buttonText(%q) == %q, want %d characters ending with ellipsis", long, text, maxButtonTextLen)
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Telegram doesn't accept callback data longer than 64 bytes. Words may be long and contain
+// multibyte characters, so buttons never carry the word itself. Instead the word is replaced
+// by a short token that is resolved back with callbackTokens.
+const maxCallbackDataLen = 64
+
+const callbackSeparator = ":"
+
+// Callback actions
+const (
+	actionPhrases  = "ph"
+	actionWord     = "w"
+	actionSave     = "sv"
+	actionReveal   = "qr"
+	actionSettings = "st"
+	actionExpand   = "x"
+	actionSay      = "say"
+	actionNoop     = "nop"
+)
+
+// Settings changed by settings buttons
+const (
+	settingPageSize = iota
+	settingDirection
+	settingPhrases
+)
+
+// legacyPhrasesPrefix is used by buttons sent before tokens were introduced
+const legacyPhrasesPrefix = "phrases:"
+
+// Button labels longer than this are shortened
+const maxButtonTextLen = 30
+
+// buttonText shortens text to fit the button
+func buttonText(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxButtonTextLen {
+		return text
+	}
+	return string(runes[:maxButtonTextLen-1]) + "…"
+}
+
+// callbackData describes the state carried by inline button
+type callbackData struct {
+	Action string
+	Token  string
+	Args   []int
+}
+
+// String encodes callback data into the form suitable for inline button
+func (d callbackData) String() string {
+	parts := []string{d.Action, d.Token}
+	for _, a := range d.Args {
+		parts = append(parts, strconv.Itoa(a))
+	}
+	return strings.Join(parts, callbackSeparator)
+}
+
+// Arg returns argument with provided index or def if there's no such argument
+func (d callbackData) Arg(i int, def int) int {
+	if i < len(d.Args) {
+		return d.Args[i]
+	}
+	return def
+}
+
+// parseCallbackData decodes data received from inline button
+func parseCallbackData(data string) (callbackData, error) {
+	if len(data) > maxCallbackDataLen {
+		return callbackData{}, fmt.Errorf("callback data is too long (%d bytes)", len(data))
+	}
+
+	parts := strings.Split(data, callbackSeparator)
+	d := callbackData{Action: parts[0]}
+	if len(parts) < 2 {
+		return d, nil
+	}
+
+	d.Token = parts[1]
+	for _, p := range parts[2:] {
+		a, err := strconv.Atoi(p)
+		if err != nil {
+			return callbackData{}, fmt.Errorf("bad callback argument %q", p)
+		}
+		d.Args = append(d.Args, a)
+	}
+
+	return d, nil
+}
+
+// callbackTokens keeps mapping between short tokens and words they stand for. Tokens are
+// stored in the database, so buttons keep working after restart. Only limited number of
+// words is kept, the oldest ones are forgotten first. Recent tokens are also kept in memory,
+// so the database is written only for new words.
+type callbackTokens struct {
+	db     *bolt.DB
+	logger *slog.Logger
+	limit  int
+
+	mu    sync.Mutex
+	words map[string]string
+	order []string
+}
+
+// newCallbackTokens creates token storage in the database opened by openDatabase that
+// remembers up to limit words
+func newCallbackTokens(db *bolt.DB, limit int, logger *slog.Logger) *callbackTokens {
+	return &callbackTokens{
+		db:     db,
+		logger: logger,
+		limit:  limit,
+		words:  make(map[string]string),
+	}
+}
+
+// tokenCandidate returns n-th possible token of the word. Tokens are short hashes, so
+// different words may get the same one, the next candidate is tried then.
+func tokenCandidate(word string, n int) string {
+	if n > 0 {
+		word += "\x00" + strconv.Itoa(n)
+	}
+	sum := sha1.Sum([]byte(word))
+	return base64.RawURLEncoding.EncodeToString(sum[:6])
+}
+
+// Token returns the token for the word. The same word gets the same token while it's kept,
+// tokens taken by other words are skipped.
+func (c *callbackTokens) Token(word string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for n := 0; ; n++ {
+		token := tokenCandidate(word, n)
+		if w, ok := c.words[token]; ok {
+			if w == word {
+				return token
+			}
+			continue
+		}
+
+		stored, err := c.store(token, word)
+		if err != nil {
+			// Token still works until restart
+			c.logger.Warn("unable to store callback token", "word", word, "err", err)
+			stored = word
+		}
+		c.remember(token, stored)
+		if stored == word {
+			return token
+		}
+	}
+}
+
+// store saves the token unless it's already taken and returns the word stored for it
+func (c *callbackTokens) store(token, word string) (string, error) {
+	stored, err := c.load(token)
+	if err != nil || stored != "" {
+		return stored, err
+	}
+
+	err = c.db.Update(func(tx *bolt.Tx) error {
+		b, order := tx.Bucket(tokensBucket), tx.Bucket(tokenOrderBucket)
+		if w := b.Get([]byte(token)); w != nil {
+			stored = string(w)
+			return nil
+		}
+		stored = word
+
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := b.Put([]byte(token), []byte(word)); err != nil {
+			return err
+		}
+		if err := order.Put(sequenceKey(seq), []byte(token)); err != nil {
+			return err
+		}
+
+		if seq <= uint64(c.limit) {
+			return nil
+		}
+		oldest := sequenceKey(seq - uint64(c.limit))
+		if t := order.Get(oldest); t != nil {
+			if err := b.Delete(t); err != nil {
+				return err
+			}
+		}
+		return order.Delete(oldest)
+	})
+	return stored, err
+}
+
+// load returns the word stored for the token or empty string if there's no such token
+func (c *callbackTokens) load(token string) (string, error) {
+	word := ""
+	err := c.db.View(func(tx *bolt.Tx) error {
+		word = string(tx.Bucket(tokensBucket).Get([]byte(token)))
+		return nil
+	})
+	return word, err
+}
+
+// remember keeps the token in memory, the oldest ones are forgotten first
+func (c *callbackTokens) remember(token, word string) {
+	c.words[token] = word
+	c.order = append(c.order, token)
+
+	if len(c.order) > c.limit {
+		delete(c.words, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// Word resolves token back to the word
+func (c *callbackTokens) Word(token string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if w, ok := c.words[token]; ok {
+		return w, true
+	}
+
+	w, err := c.load(token)
+	if err != nil {
+		c.logger.Warn("unable to load callback token", "token", token, "err", err)
+		return "", false
+	}
+	if w == "" {
+		return "", false
+	}
+	c.remember(token, w)
+	return w, true
+}
+
+func sequenceKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
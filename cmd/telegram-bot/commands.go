@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+)
+
+// commandHandler processes the message with a command. Context carries trace of the update.
+type commandHandler func(ctx context.Context, message *tgbotapi.Message)
+
+// botCommand describes a command shown in telegram menu
+type botCommand struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+// commandRouter calls handlers for the commands
+type commandRouter struct {
+	commands []botCommand
+	handlers map[string]commandHandler
+	unknown  commandHandler
+}
+
+func newCommandRouter(unknown commandHandler) *commandRouter {
+	return &commandRouter{
+		handlers: make(map[string]commandHandler),
+		unknown:  unknown,
+	}
+}
+
+// Handle registers handler for the command. Commands without description are not shown
+// to the users.
+func (r *commandRouter) Handle(name string, description string, handler commandHandler) {
+	r.handlers[name] = handler
+	if description != "" {
+		r.commands = append(r.commands, botCommand{name, description})
+	}
+}
+
+// Route calls the handler of the command from the message
+func (r *commandRouter) Route(ctx context.Context, message *tgbotapi.Message) {
+	handler, ok := r.handlers[message.Command()]
+	if !ok {
+		handler = r.unknown
+	}
+	handler(ctx, message)
+}
+
+// Commands returns commands that are shown to the users
+func (r *commandRouter) Commands() []botCommand {
+	return r.commands
+}
+
+// newBotRouter registers all commands supported by the bot
+func newBotRouter(bot *Bot) *commandRouter {
+	r := newCommandRouter(bot.handleUnknownCommand)
+
+	r.Handle("start", "Начать работу с ботом", bot.handleStart)
+	r.Handle("help", "Как пользоваться ботом", bot.handleHelp)
+	r.Handle("t", "Перевести слово", bot.handleTranslate)
+	r.Handle("say", "Произнести слово", bot.handleSay)
+	r.Handle("list", "Сохранённые слова", bot.handleList)
+	r.Handle("quiz", "Повторить сохранённые слова", bot.handleQuiz)
+	r.Handle("daily", "Подписаться на слово дня", bot.handleDaily)
+	r.Handle("export", "Выгрузить сохранённые слова в CSV", bot.handleExport)
+	r.Handle("settings", "Настройки", bot.handleSettings)
+	r.Handle("about", "О боте", bot.handleAbout)
+
+	// Administrative commands aren't shown in the menu
+	r.Handle("block", "", func(ctx context.Context, m *tgbotapi.Message) { bot.handleBlock(m, true) })
+	r.Handle("unblock", "", func(ctx context.Context, m *tgbotapi.Message) { bot.handleBlock(m, false) })
+	r.Handle("blocked", "", bot.handleBlocked)
+
+	return r
+}
+
+// registerCommands sends the list of commands to telegram, so they're shown in the menu
+func (bot *Bot) registerCommands() error {
+	data, err := json.Marshal(bot.router.Commands())
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Add("commands", string(data))
+
+	if _, err = bot.api.MakeRequest("setMyCommands", params); err != nil {
+		return errors.Wrap(err, "unable to register commands")
+	}
+	return nil
+}
+
+// sendMarkdown writes markdown formatted message to the chat
+func (bot *Bot) sendMarkdown(chatID int64, text string) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.DisableWebPagePreview = true
+	if _, err := bot.api.Send(msg); err != nil {
+		bot.logger.Error("unable to send message", "chat_id", chatID, "err", err)
+	}
+}
+
+func (bot *Bot) handleStart(ctx context.Context, message *tgbotapi.Message) {
+	bot.sendMarkdown(message.Chat.ID, bot.templates.Start(message.From))
+}
+
+func (bot *Bot) handleHelp(ctx context.Context, message *tgbotapi.Message) {
+	bot.sendMarkdown(message.Chat.ID, bot.templates.Help(bot.router.Commands()))
+}
+
+func (bot *Bot) handleAbout(ctx context.Context, message *tgbotapi.Message) {
+	bot.sendMarkdown(message.Chat.ID, bot.templates.About())
+}
+
+func (bot *Bot) handleUnknownCommand(ctx context.Context, message *tgbotapi.Message) {
+	// Groups may have other bots, their commands aren't answered
+	if isGroup(message.Chat) {
+		return
+	}
+	bot.respond(message.Chat.ID, "Я не знаю команду /"+message.Command()+". Список команд — /help")
+}
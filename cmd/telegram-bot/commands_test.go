@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// commandMessage creates message with the command sent by the user
+func commandMessage(userID int, text string) *tgbotapi.Message {
+	command := strings.Fields(text)[0]
+	return &tgbotapi.Message{
+		From:     &tgbotapi.User{ID: userID},
+		Chat:     &tgbotapi.Chat{ID: int64(userID)},
+		Text:     text,
+		Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}},
+	}
+}
+
+func TestCommandRouter(t *testing.T) {
+	var called []string
+	stub := func(name string) commandHandler {
+		return func(ctx context.Context, message *tgbotapi.Message) {
+			called = append(called, name+":"+message.CommandArguments())
+		}
+	}
+
+	r := newCommandRouter(stub("unknown"))
+	r.Handle("t", "Перевести слово", stub("t"))
+	r.Handle("help", "Как пользоваться ботом", stub("help"))
+	r.Handle("block", "", stub("block"))
+
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"/t pes", "t:pes"},
+		{"/t@slovnik_bot kočka", "t:kočka"},
+		{"/help", "help:"},
+		{"/block 5", "block:5"},
+		{"/unknown now", "unknown:now"},
+		{"/T pes", "unknown:pes"},
+	}
+	for _, c := range cases {
+		called = nil
+		r.Route(context.Background(), commandMessage(1, c.text))
+		if len(called) != 1 || called[0] != c.want {
+			t.Errorf("Route(%q) called %q, want %q", c.text, called, c.want)
+		}
+	}
+
+	// Commands without description are hidden from the menu
+	want := []botCommand{{"t", "Перевести слово"}, {"help", "Как пользоваться ботом"}}
+	if got := r.Commands(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Commands() == %v, want %v", got, want)
+	}
+}
+
+func TestAdminCommands(t *testing.T) {
+	bot, telegram := newTestBot(t, stubTranslator{})
+	bot.admins = []int{1}
+
+	cases := []struct {
+		userID  int
+		text    string
+		blocked bool
+	}{
+		{2, "/block 5", false},
+		{1, "/block 5", true},
+		{2, "/unblock 5", true},
+		{1, "/unblock 5", false},
+	}
+	for _, c := range cases {
+		bot.router.Route(context.Background(), commandMessage(c.userID, c.text))
+		if blocked, err := bot.blockList.IsBlocked(5); err != nil || blocked != c.blocked {
+			t.Errorf("IsBlocked(5) after %q by user %d == %v, %v, want %v", c.text, c.userID, blocked, err, c.blocked)
+		}
+	}
+
+	// Users that aren't administrators are told about it
+	denied := 0
+	for _, m := range telegram.Calls("sendMessage") {
+		if m.Get("chat_id") == "2" && strings.Contains(m.Get("text"), "администраторам") {
+			denied++
+		}
+	}
+	if denied != 2 {
+		t.Errorf("denied %d administrative commands, want 2", denied)
+	}
+}
@@ -2,47 +2,140 @@ package main
 
 import (
 	"fmt"
-	"os"
-)
+	"strings"
+	"time"
 
-const (
-	envBotID       = "SLOVNIK_BOT_ID"
-	envAPIURL      = "SLOVNIK_API_URL"
-	envWebhookHost = "SLOVNIK_WEBHOOK_HOST"
+	"github.com/rpeshkov/slovnik/config"
 )
 
 // Config represents configuration information
 type Config struct {
-	BotID      string
-	SlovnikURL string
+	config.Logging
+	config.Tracing
+
+	BotID      string        `key:"bot_id" required:"true" secret:"true" desc:"Token of telegram bot"`
+	SlovnikURL string        `key:"api_url" required:"true" desc:"Address of slovnik API server"`
+	APITimeout time.Duration `key:"api_timeout" default:"10s" min:"1ms" desc:"Timeout of requests to API server"`
+
+	// APIKey is sent to API server, it's required when the server has API keys configured
+	APIKey string `key:"api_key" secret:"true" desc:"API key of the bot, required when API server checks keys"`
+
+	// WebhookHost is a public address of the bot. Updates are polled when it's empty.
+	WebhookHost string `key:"webhook_host" example:"https://bot.example.com" desc:"Public address of the bot, updates are polled when it's empty"`
+
+	// WebhookURL is an address of webhook registered in telegram, it's made of host and path
 	WebhookURL string
+
+	// WebhookListen is an address of HTTP server. It serves health checks and metrics in
+	// both modes and receives updates in webhook mode.
+	WebhookListen string `key:"webhook_listen" default:"0.0.0.0:8080" desc:"Address of HTTP server of webhook, health checks and metrics"`
+
+	// WebhookPath is a path of webhook URL that receives updates
+	WebhookPath string `key:"webhook_path" default:"/webhook" desc:"Path of webhook URL"`
+
+	// WebhookCert and WebhookKey are paths to TLS certificate and key. Webhook server uses
+	// plain HTTP when they're empty, e.g. behind reverse proxy.
+	WebhookCert string `key:"webhook_cert" desc:"TLS certificate of webhook server"`
+	WebhookKey  string `key:"webhook_key" desc:"TLS key of webhook server"`
+
+	// WebhookSecret is sent by telegram in every webhook request, requests without it
+	// are rejected. Secret isn't checked when it's empty.
+	WebhookSecret string `key:"webhook_secret" secret:"true" desc:"Secret token that telegram sends with webhook requests"`
+
+	// PhrasesPageSize is a number of phrases shown on one page
+	PhrasesPageSize int `key:"phrases_page_size" default:"5" min:"1" desc:"Number of phrases shown on one page"`
+
+	// DBPath is a path to database file with users data
+	DBPath string `key:"db_path" default:"slovnik.db" example:"/data/slovnik.db" desc:"Database file with users data"`
+
+	// ShutdownTimeout limits the time given to in-flight updates on shutdown
+	ShutdownTimeout time.Duration `key:"shutdown_timeout" default:"10s" desc:"Time given to in-flight updates on shutdown"`
+
+	// Workers is a number of updates processed concurrently
+	Workers int `key:"workers" default:"8" min:"1" desc:"Number of updates processed concurrently"`
+
+	// QueueSize is a number of updates that may wait for processing
+	QueueSize int `key:"queue_size" default:"100" min:"1" desc:"Number of updates that may wait for processing"`
+
+	// ChatRateLimit is a number of requests per minute allowed for one chat
+	ChatRateLimit int `key:"chat_rate_limit" default:"20" min:"1" desc:"Requests per minute allowed for one chat"`
+
+	// GlobalRateLimit is a number of requests per minute allowed for all chats together
+	GlobalRateLimit int `key:"global_rate_limit" default:"600" min:"1" desc:"Requests per minute allowed for all chats together"`
+
+	// MaxInputLength is a maximum length of text that is translated. Text may be a sentence,
+	// the number of its words that are looked up is limited by MaxTokens.
+	MaxInputLength int `key:"max_input_length" default:"500" min:"1" desc:"Maximum length of text that is translated"`
+
+	// MaxTokens is a maximum number of words looked up when message contains several words
+	MaxTokens int `key:"max_tokens" default:"10" min:"1" desc:"Maximum number of words looked up for one message"`
+
+	// Admins contains IDs of users that may use administrative commands
+	Admins []int `key:"admins" desc:"Comma separated IDs of users that may use administrative commands"`
+
+	// WhisperModel is a path to speech recognition model. Voice messages aren't supported
+	// when it's empty.
+	WhisperModel string `key:"whisper_model" desc:"Speech recognition model, voice messages aren't supported when it's empty"`
+
+	// EspeakBinary is a path to espeak-ng executable used for pronunciation. Pronunciation
+	// isn't supported when it's empty.
+	EspeakBinary string `key:"espeak_bin" desc:"espeak-ng executable, pronunciation isn't supported when it's empty"`
+
+	// SpeechTimeout limits the time of speech recognition and synthesis
+	SpeechTimeout time.Duration `key:"speech_timeout" default:"30s" desc:"Time given to speech recognition or synthesis of one message"`
+
+	// DailyWords is a path to the list of words that are sent as word of the day
+	DailyWords string `key:"daily_words" default:"daily-words.txt" desc:"List of words sent as word of the day"`
+
+	// Timezone is used for word of the day subscriptions that don't specify it
+	Timezone string `key:"timezone" default:"Europe/Prague" desc:"Timezone of word of the day subscriptions that don't specify it"`
+}
+
+// LoadConfig loads bot configuration from configuration file, environment and command line
+func LoadConfig(args []string) (*Config, error) {
+	c := &Config{}
+	if err := config.Load("telegram-bot", c, args); err != nil {
+		return nil, err
+	}
+
+	if c.WebhookHost != "" {
+		c.WebhookURL = strings.TrimSuffix(c.WebhookHost, "/") + c.WebhookPath
+	}
+	return c, nil
 }
 
-// InitConfig initializes bot configuration
-func InitConfig() (*Config, error) {
-	botID, ok := os.LookupEnv(envBotID)
-	if !ok {
-		return nil, fmt.Errorf("%s is not set", envBotID)
+// Validate checks options that depend on each other
+func (c *Config) Validate() error {
+	if !strings.HasPrefix(c.WebhookPath, "/") {
+		return fmt.Errorf("%sWEBHOOK_PATH must start with /", config.EnvPrefix)
 	}
 
-	slovnikURL, ok := os.LookupEnv(envAPIURL)
-	if !ok {
-		return nil, fmt.Errorf("%s is not set", envAPIURL)
+	if (c.WebhookCert == "") != (c.WebhookKey == "") {
+		return fmt.Errorf("%[1]sWEBHOOK_CERT and %[1]sWEBHOOK_KEY must be set together", config.EnvPrefix)
 	}
 
-	webhookURL := ""
-	webhookHost, ok := os.LookupEnv(envWebhookHost)
-	if ok {
-		webhookURL = fmt.Sprintf("%s/bot%s", webhookHost, botID)
+	if !validSecret(c.WebhookSecret) {
+		return fmt.Errorf("%sWEBHOOK_SECRET must be up to 256 characters A-Z, a-z, 0-9, _ and -", config.EnvPrefix)
 	}
 
-	config := Config{
-		BotID:      botID,
-		SlovnikURL: slovnikURL,
-		WebhookURL: webhookURL,
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		return fmt.Errorf("%sTIMEZONE must be a timezone name like Europe/Prague", config.EnvPrefix)
 	}
 
-	return &config, nil
+	return nil
+}
+
+// validSecret checks that webhook secret contains only characters allowed by telegram
+func validSecret(secret string) bool {
+	if len(secret) > 256 {
+		return false
+	}
+	for _, ch := range secret {
+		if !(ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '_' || ch == '-') {
+			return false
+		}
+	}
+	return true
 }
 
 // IsWebhook returns true if webhook URL is set
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/rpeshkov/slovnik"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// Subscriptions are checked this often
+	dailyCheckInterval = time.Minute
+
+	// Word that wasn't delivered in time (e.g. bot was down) is still sent within this period
+	dailyGracePeriod = 6 * time.Hour
+
+	// dailySourceSaved means the word is taken from chat's saved words
+	dailySourceSaved = "saved"
+
+	// Telegram allows bots about 30 messages per second, words of the day leave some of
+	// them to the answers
+	dailyMessagesPerSecond = 25
+)
+
+// errNoDailyWord means that there's no word to send today. It isn't retried until the next
+// day, unlike failures of the dictionary or telegram.
+var errNoDailyWord = errors.New("no word of the day")
+
+// Subscription describes when the chat wants to receive the word of the day
+type Subscription struct {
+	ChatID   int64  `json:"chatID"`
+	Hour     int    `json:"hour"`
+	Minute   int    `json:"minute"`
+	Timezone string `json:"timezone"`
+
+	// Source is empty for frequency list or dailySourceSaved for saved words
+	Source string `json:"source,omitempty"`
+
+	// LastSent is the time of last delivery, or of the day skipped for lack of word, or
+	// subscription time if nothing was sent yet
+	LastSent time.Time `json:"lastSent"`
+}
+
+// due returns the latest moment before now when the word should have been sent
+func (s *Subscription) due(now time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	local := now.In(loc)
+	due := time.Date(local.Year(), local.Month(), local.Day(), s.Hour, s.Minute, 0, 0, loc)
+	if due.After(now) {
+		due = due.AddDate(0, 0, -1)
+	}
+	return due, nil
+}
+
+// ShouldSend reports whether the word of the day should be sent now. Error is returned
+// when timezone of the subscription is unknown.
+func (s *Subscription) ShouldSend(now time.Time) (bool, error) {
+	due, err := s.due(now)
+	if err != nil {
+		return false, err
+	}
+	return s.LastSent.Before(due) && now.Sub(due) < dailyGracePeriod, nil
+}
+
+// SubscriptionStore keeps word of the day subscriptions
+type SubscriptionStore interface {
+	Save(s *Subscription) error
+	Delete(chatID int64) error
+	List() ([]*Subscription, error)
+}
+
+// boltSubscriptions is a SubscriptionStore that keeps subscriptions in bolt database
+type boltSubscriptions struct {
+	db *bolt.DB
+}
+
+// newBoltSubscriptions creates subscription store in the database opened by openDatabase
+func newBoltSubscriptions(db *bolt.DB) *boltSubscriptions {
+	return &boltSubscriptions{db}
+}
+
+// Save creates or replaces subscription of the chat
+func (b *boltSubscriptions) Save(s *Subscription) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(dailyBucket).Put(chatKey(s.ChatID), data)
+	})
+}
+
+// Delete removes subscription of the chat
+func (b *boltSubscriptions) Delete(chatID int64) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(dailyBucket).Delete(chatKey(chatID))
+	})
+}
+
+// List returns all subscriptions
+func (b *boltSubscriptions) List() ([]*Subscription, error) {
+	subscriptions := []*Subscription{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(dailyBucket).ForEach(func(k, data []byte) error {
+			s := &Subscription{}
+			if err := json.Unmarshal(data, s); err != nil {
+				return errors.Wrapf(err, "bad subscription %q", k)
+			}
+			subscriptions = append(subscriptions, s)
+			return nil
+		})
+	})
+	return subscriptions, err
+}
+
+// loadWordList reads words from file, one word per line. Empty lines and lines
+// starting with # are skipped.
+func loadWordList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load word list")
+	}
+	defer f.Close()
+
+	words := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	return words, scanner.Err()
+}
+
+// pickDaily selects the word for the day. The word changes once a day, different seeds
+// shuffle the words differently.
+func pickDaily(words []string, day time.Time, seed int64) string {
+	if len(words) == 0 {
+		return ""
+	}
+
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s:%d", day.Format("2006-01-02"), seed)
+	return words[h.Sum32()%uint32(len(words))]
+}
+
+// parseSubscription parses arguments of /daily command: time in HH:MM format optionally
+// followed by timezone name and "saved" keyword
+func parseSubscription(args string, defaultTimezone string) (*Subscription, error) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return nil, errors.New("time is not specified")
+	}
+
+	s := &Subscription{Timezone: defaultTimezone}
+	if _, err := fmt.Sscanf(fields[0], "%d:%d", &s.Hour, &s.Minute); err != nil {
+		return nil, errors.Errorf("bad time %q", fields[0])
+	}
+	if s.Hour < 0 || s.Hour > 23 || s.Minute < 0 || s.Minute > 59 {
+		return nil, errors.Errorf("bad time %q", fields[0])
+	}
+
+	for _, f := range fields[1:] {
+		if f == dailySourceSaved {
+			s.Source = dailySourceSaved
+			continue
+		}
+
+		if _, err := time.LoadLocation(f); err != nil {
+			return nil, errors.Errorf("unknown timezone %q", f)
+		}
+		s.Timezone = f
+	}
+
+	return s, nil
+}
+
+// handleDaily subscribes the chat to word of the day or cancels the subscription
+func (bot *Bot) handleDaily(ctx context.Context, message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	args := strings.TrimSpace(message.CommandArguments())
+
+	if args == "off" {
+		if err := bot.subscriptions.Delete(chatID); err != nil {
+			bot.respondError(chatID, "Something bad happened :(")
+			bot.logger.Error("unable to unsubscribe", "chat_id", chatID, "err", err)
+			return
+		}
+		bot.respond(chatID, "Подписка на слово дня отменена")
+		return
+	}
+
+	s, err := parseSubscription(args, bot.timezone)
+	if err != nil {
+		bot.respond(chatID, "Укажите время, например: /daily 08:30\n"+
+			"Можно добавить часовой пояс и слово saved, чтобы получать сохранённые слова: "+
+			"/daily 08:30 Europe/Prague saved\n"+
+			"Отменить подписку: /daily off")
+		return
+	}
+
+	s.ChatID = chatID
+	s.LastSent = time.Now()
+	if err = bot.subscriptions.Save(s); err != nil {
+		bot.respondError(chatID, "Something bad happened :(")
+		bot.logger.Error("unable to subscribe", "chat_id", chatID, "err", err)
+		return
+	}
+
+	bot.respond(chatID, fmt.Sprintf("Буду присылать слово дня в %02d:%02d (%s)", s.Hour, s.Minute, s.Timezone))
+}
+
+// runDaily sends words of the day until ctx is cancelled
+func (bot *Bot) runDaily(ctx context.Context) {
+	ticker := time.NewTicker(dailyCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		bot.sendDaily(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendDaily delivers words of the day to subscriptions that are due
+func (bot *Bot) sendDaily(ctx context.Context, now time.Time) {
+	subscriptions, err := bot.subscriptions.List()
+	if err != nil {
+		bot.logger.Error("unable to list subscriptions", "err", err)
+		return
+	}
+
+	throttle := time.NewTicker(time.Second / dailyMessagesPerSecond)
+	defer throttle.Stop()
+
+	for _, s := range subscriptions {
+		send, err := s.ShouldSend(now)
+		if err != nil {
+			bot.logger.Error("bad subscription timezone", "chat_id", s.ChatID, "timezone", s.Timezone, "err", err)
+			continue
+		}
+		if !send {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-throttle.C:
+		}
+
+		err = bot.sendWordOfDay(ctx, s, now)
+		if isBlockedByUser(err) {
+			bot.logger.Info("chat is unavailable, removing daily subscription", "chat_id", s.ChatID, "err", err)
+			if err = bot.subscriptions.Delete(s.ChatID); err != nil {
+				bot.logger.Error("unable to unsubscribe", "chat_id", s.ChatID, "err", err)
+			}
+			continue
+		}
+		if errors.Cause(err) == errNoDailyWord {
+			bot.logger.Warn("word of the day is skipped", "chat_id", s.ChatID, "err", err)
+		} else if err != nil {
+			bot.logger.Error("unable to send word of the day", "chat_id", s.ChatID, "err", err)
+			continue
+		}
+
+		s.LastSent = now
+		if err = bot.subscriptions.Save(s); err != nil {
+			bot.logger.Error("unable to save subscription", "chat_id", s.ChatID, "err", err)
+		}
+	}
+}
+
+// sendWordOfDay selects the word for the subscription and sends its translation with phrases.
+// All chats get the same word from the common list on the same local date, saved words are
+// shuffled differently for each chat.
+func (bot *Bot) sendWordOfDay(ctx context.Context, s *Subscription, now time.Time) error {
+	// Date of the delivery in subscription timezone, not the date of the server
+	day, err := s.due(now)
+	if err != nil {
+		return err
+	}
+
+	candidates := bot.dailyWords
+	var seed int64
+	if s.Source == dailySourceSaved {
+		entries, err := bot.vocabulary.List(s.ChatID)
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			candidates = []string{}
+			for _, e := range entries {
+				candidates = append(candidates, e.Word.Word)
+			}
+			seed = s.ChatID
+		}
+	}
+
+	word := pickDaily(candidates, day, seed)
+	if word == "" {
+		return errors.Wrap(errNoDailyWord, "word list is empty")
+	}
+
+	words, err := bot.translator.Translate(ctx, word, slovnik.DetectLanguage(word))
+	if err != nil {
+		return err
+	}
+	if !slovnik.IsEntry(words, word) {
+		return errors.Wrapf(errNoDailyWord, "%q is not found", word)
+	}
+
+	settings := bot.chatSettings(s.ChatID)
+	text := "📅 *Слово дня*\n\n" + bot.templates.Translation(words)
+	if len(words[0].Samples) > 0 {
+		text += bot.templates.Phrases(paginatePhrases(words[0], "", 0, settings.PhrasesPageSize))
+	}
+
+	msg := tgbotapi.NewMessage(s.ChatID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	if keyboard := bot.messageKeyboard(words, word, settings); keyboard != nil {
+		msg.ReplyMarkup = keyboard
+	}
+
+	_, err = bot.api.Send(msg)
+	return err
+}
+
+// isBlockedByUser reports whether error means that the bot can't write to the chat anymore
+func isBlockedByUser(err error) bool {
+	if err == nil {
+		return false
+	}
+	text := err.Error()
+	return strings.Contains(text, "Forbidden") || strings.Contains(text, "chat not found")
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rpeshkov/slovnik"
+)
+
+func TestParseSubscription(t *testing.T) {
+	cases := []struct {
+		args     string
+		ok       bool
+		hour     int
+		minute   int
+		timezone string
+		source   string
+	}{
+		{"08:30", true, 8, 30, "Europe/Prague", ""},
+		{"7:05 Europe/Moscow", true, 7, 5, "Europe/Moscow", ""},
+		{"21:00 saved", true, 21, 0, "Europe/Prague", dailySourceSaved},
+		{"21:00 UTC saved", true, 21, 0, "UTC", dailySourceSaved},
+		{"", false, 0, 0, "", ""},
+		{"24:00", false, 0, 0, "", ""},
+		{"12:60", false, 0, 0, "", ""},
+		{"morning", false, 0, 0, "", ""},
+		{"08:30 Mars/Olympus", false, 0, 0, "", ""},
+	}
+
+	for _, c := range cases {
+		s, err := parseSubscription(c.args, "Europe/Prague")
+		if (err == nil) != c.ok {
+			t.Errorf("parseSubscription(%q) error == %v, want ok %v", c.args, err, c.ok)
+			continue
+		}
+		if !c.ok {
+			continue
+		}
+		if s.Hour != c.hour || s.Minute != c.minute || s.Timezone != c.timezone || s.Source != c.source {
+			t.Errorf("parseSubscription(%q) == %+v, want %02d:%02d %s %q", c.args, s, c.hour, c.minute, c.timezone, c.source)
+		}
+	}
+}
+
+func TestSubscriptionShouldSend(t *testing.T) {
+	prague, err := time.LoadLocation("Europe/Prague")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	s := &Subscription{Hour: 8, Minute: 30, Timezone: "Europe/Prague"}
+	subscribed := time.Date(2018, 3, 10, 12, 0, 0, 0, prague)
+
+	cases := []struct {
+		lastSent time.Time
+		now      time.Time
+		want     bool
+	}{
+		// Subscribed after today's time, the first word comes tomorrow
+		{subscribed, time.Date(2018, 3, 10, 18, 0, 0, 0, prague), false},
+		{subscribed, time.Date(2018, 3, 11, 8, 29, 0, 0, prague), false},
+		{subscribed, time.Date(2018, 3, 11, 8, 30, 0, 0, prague), true},
+
+		// Time is compared in subscription timezone
+		{subscribed, time.Date(2018, 3, 11, 7, 31, 0, 0, time.UTC), true},
+
+		// Already sent today
+		{time.Date(2018, 3, 11, 8, 30, 0, 0, prague), time.Date(2018, 3, 11, 12, 0, 0, 0, prague), false},
+
+		// Bot was down at the time, the word is sent after restart within grace period
+		{subscribed, time.Date(2018, 3, 11, 13, 0, 0, 0, prague), true},
+		{subscribed, time.Date(2018, 3, 11, 15, 0, 0, 0, prague), false},
+	}
+
+	for _, c := range cases {
+		s.LastSent = c.lastSent
+		if got, err := s.ShouldSend(c.now); err != nil || got != c.want {
+			t.Errorf("ShouldSend(%v) with last sent %v == %v, %v, want %v", c.now, c.lastSent, got, err, c.want)
+		}
+	}
+
+	s.Timezone = "Mars/Olympus"
+	if _, err := s.ShouldSend(subscribed); err == nil {
+		t.Errorf("ShouldSend() with unknown timezone error == nil, want error")
+	}
+}
+
+func TestPickDaily(t *testing.T) {
+	words := []string{"den", "noc", "voda", "země", "slovo"}
+	day := time.Date(2018, 3, 10, 8, 30, 0, 0, time.UTC)
+
+	first := pickDaily(words, day, 1)
+	if again := pickDaily(words, day.Add(time.Hour), 1); again != first {
+		t.Errorf("pickDaily() == %q later the same day, want %q", again, first)
+	}
+
+	// Different seeds shuffle the words
+	differs := false
+	for seed := int64(2); seed < 20; seed++ {
+		differs = differs || pickDaily(words, day, seed) != first
+	}
+	if !differs {
+		t.Errorf("pickDaily() == %q for all seeds, want words to be shuffled", first)
+	}
+
+	if w := pickDaily(nil, day, 1); w != "" {
+		t.Errorf("pickDaily(nil) == %q, want empty string", w)
+	}
+}
+
+func TestSendDaily(t *testing.T) {
+	translator := stubTranslator{}
+	bot, telegram := newTestBot(t, translator)
+	bot.dailyWords = []string{"den", "noc", "voda", "země", "slovo"}
+	for _, w := range bot.dailyWords {
+		translator[w] = []*slovnik.Word{{Word: w, Translations: []string{"перевод"}}}
+	}
+
+	now := time.Date(2018, 3, 10, 8, 30, 0, 0, time.UTC)
+	chats := []int64{1, 2, 3, 4}
+	for _, id := range chats {
+		s := &Subscription{ChatID: id, Hour: 8, Timezone: "UTC", LastSent: now.AddDate(0, 0, -1)}
+		if err := bot.subscriptions.Save(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	start := time.Now()
+	bot.sendDaily(context.Background(), now)
+
+	if min := time.Duration(len(chats)) * time.Second / dailyMessagesPerSecond; time.Since(start) < min {
+		t.Errorf("words are sent in %v, want at least %v", time.Since(start), min)
+	}
+
+	calls := telegram.Calls("sendMessage")
+	if len(calls) != len(chats) {
+		t.Fatalf("sendMessage is called %d times, want %d", len(calls), len(chats))
+	}
+	for _, c := range calls[1:] {
+		if c.Get("text") != calls[0].Get("text") {
+			t.Errorf("word of the day %q differs from %q, want the same word in all chats", c.Get("text"), calls[0].Get("text"))
+		}
+	}
+}
+
+func TestSendWordOfDayLocalDate(t *testing.T) {
+	translator := stubTranslator{}
+	bot, telegram := newTestBot(t, translator)
+	for i := 0; i < 30; i++ {
+		w := fmt.Sprintf("slovo%d", i)
+		bot.dailyWords = append(bot.dailyWords, w)
+		translator[w] = []*slovnik.Word{{Word: w, WordType: "s", Translations: []string{"слово"}}}
+	}
+
+	// 08:00 of March 10 in both timezones, it's still March 9 in UTC for Tokyo
+	tokyo := time.Date(2018, 3, 9, 23, 0, 0, 0, time.UTC)
+	prague := time.Date(2018, 3, 10, 7, 0, 0, 0, time.UTC)
+	if pickDaily(bot.dailyWords, tokyo, 0) == pickDaily(bot.dailyWords, prague, 0) {
+		t.Fatal("words of the server dates are the same, test can't tell them apart")
+	}
+
+	for _, c := range []struct {
+		timezone string
+		now      time.Time
+	}{{"Asia/Tokyo", tokyo}, {"Europe/Prague", prague}} {
+		s := &Subscription{ChatID: 1, Hour: 8, Timezone: c.timezone}
+		if err := bot.sendWordOfDay(context.Background(), s, c.now); err != nil {
+			t.Skip(err)
+		}
+	}
+
+	calls := telegram.Calls("sendMessage")
+	if len(calls) != 2 || calls[0].Get("text") != calls[1].Get("text") {
+		t.Errorf("words of the day == %v, want the same word on the same local date", calls)
+	}
+}
+
+func TestSendWordOfDaySuggestion(t *testing.T) {
+	bot, telegram := newTestBot(t, stubTranslator{"kocak": {{Word: "kočka", Translations: []string{"кошка"}}}})
+	bot.dailyWords = []string{"kocak"}
+
+	s := &Subscription{ChatID: 1, Hour: 8, Timezone: "UTC"}
+	if err := bot.sendWordOfDay(context.Background(), s, time.Now()); err == nil {
+		t.Errorf("sendWordOfDay() with suggestion == nil, want error")
+	}
+	if calls := telegram.Calls("sendMessage"); len(calls) != 0 {
+		t.Errorf("sendMessage is called %d times, want suggestion not to be sent as word of the day", len(calls))
+	}
+}
+
+func TestSendDailySkipsMissingWord(t *testing.T) {
+	bot, _ := newTestBot(t, stubTranslator{})
+	bot.dailyWords = []string{"xyz"}
+
+	now := time.Date(2018, 3, 10, 8, 30, 0, 0, time.UTC)
+	if err := bot.subscriptions.Save(&Subscription{ChatID: 1, Hour: 8, Timezone: "UTC"}); err != nil {
+		t.Fatal(err)
+	}
+
+	bot.sendDaily(context.Background(), now)
+
+	// Word that isn't found isn't looked up again every minute
+	subscriptions, err := bot.subscriptions.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subscriptions) != 1 || !subscriptions[0].LastSent.Equal(now) {
+		t.Errorf("subscriptions after missing word == %+v, want the attempt to be recorded", subscriptions)
+	}
+}
+
+func TestBoltSubscriptions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slovnik-bot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := openDatabase(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	store := newBoltSubscriptions(db)
+	for _, id := range []int64{1, 2} {
+		if err = store.Save(&Subscription{ChatID: id, Hour: 9, Timezone: "UTC"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = store.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ChatID != 2 || list[0].Hour != 9 {
+		t.Errorf("List() == %+v, want only subscription of chat 2", list)
+	}
+}
+
+func TestIsBlockedByUser(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("Forbidden: bot was blocked by the user"), true},
+		{errors.New("Bad Request: chat not found"), true},
+		{errors.New("Too Many Requests: retry after 5"), false},
+	}
+
+	for _, c := range cases {
+		if got := isBlockedByUser(c.err); got != c.want {
+			t.Errorf("isBlockedByUser(%v) == %v, want %v", c.err, got, c.want)
+		}
+	}
+}
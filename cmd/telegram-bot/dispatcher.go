@@ -0,0 +1,142 @@
+package main
+
+import (
+	"log/slog"
+	"runtime/debug"
+	"sync"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// dispatcher processes updates concurrently by a pool of workers. Updates of the same chat are
+// put into the chat queue and are processed one after another in order they were received.
+type dispatcher struct {
+	mu sync.Mutex
+
+	// queues contains pending updates of each chat. Chat is present in the map while one of the
+	// workers is busy with it, even when its queue is already empty.
+	queues map[int64][]tgbotapi.Update
+
+	// ready contains chats that have pending updates and aren't taken by any worker
+	ready chan int64
+
+	// slots limits the number of queued updates. When all slots are taken Dispatch blocks
+	// until some update is processed.
+	slots chan struct{}
+
+	handle  func(tgbotapi.Update)
+	workers sync.WaitGroup
+}
+
+// newDispatcher creates dispatcher and starts workers. Not more than queueSize updates
+// can wait for processing.
+func newDispatcher(workers int, queueSize int, handle func(tgbotapi.Update)) *dispatcher {
+	d := &dispatcher{
+		queues: make(map[int64][]tgbotapi.Update),
+		ready:  make(chan int64, queueSize),
+		slots:  make(chan struct{}, queueSize),
+		handle: handle,
+	}
+
+	d.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go d.work()
+	}
+
+	return d
+}
+
+// Dispatch puts update into the queue of its chat. It blocks while all queues are full.
+func (d *dispatcher) Dispatch(update tgbotapi.Update) {
+	d.slots <- struct{}{}
+
+	chatID := updateChatID(update)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	queue, busy := d.queues[chatID]
+	d.queues[chatID] = append(queue, update)
+	if !busy {
+		d.ready <- chatID
+	}
+}
+
+// Close stops accepting updates. Workers exit after all queued updates are processed.
+func (d *dispatcher) Close() {
+	close(d.ready)
+}
+
+// Wait waits until all workers exit
+func (d *dispatcher) Wait() {
+	d.workers.Wait()
+}
+
+func (d *dispatcher) work() {
+	defer d.workers.Done()
+
+	for chatID := range d.ready {
+		for {
+			d.mu.Lock()
+			queue := d.queues[chatID]
+			if len(queue) == 0 {
+				delete(d.queues, chatID)
+				d.mu.Unlock()
+				break
+			}
+			update := queue[0]
+			d.queues[chatID] = queue[1:]
+			d.mu.Unlock()
+
+			d.process(update)
+			<-d.slots
+		}
+	}
+}
+
+// process calls handler and recovers from panics, so one bad update doesn't crash the bot
+func (d *dispatcher) process(update tgbotapi.Update) {
+	defer recoverUpdate(update)
+	d.handle(update)
+}
+
+// recoverUpdate logs panic that happened during update processing. Must be called with defer.
+func recoverUpdate(update tgbotapi.Update) {
+	if r := recover(); r != nil {
+		slog.Error("panic while handling update", "update_id", update.UpdateID,
+			"chat_id", updateChatID(update), "panic", r, "stack", string(debug.Stack()))
+	}
+}
+
+// updateChatID returns the chat the update belongs to. Updates that aren't bound to any chat
+// are ordered by the user who sent them.
+func updateChatID(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.CallbackQuery != nil:
+		return int64(update.CallbackQuery.From.ID)
+	case update.InlineQuery != nil:
+		return int64(update.InlineQuery.From.ID)
+	}
+	return 0
+}
+
+// updateType returns the kind of the update reported in metrics
+func updateType(update tgbotapi.Update) string {
+	switch {
+	case update.Message != nil && update.Message.IsCommand():
+		return "command"
+	case update.Message != nil && update.Message.Voice != nil:
+		return "voice"
+	case update.Message != nil:
+		return "message"
+	case update.CallbackQuery != nil:
+		return "callback_query"
+	case update.InlineQuery != nil:
+		return "inline_query"
+	}
+	return "other"
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func chatUpdate(id int, chatID int64) tgbotapi.Update {
+	return tgbotapi.Update{
+		UpdateID: id,
+		Message:  &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatID}},
+	}
+}
+
+func TestDispatcherOrder(t *testing.T) {
+	// Panics are expected, their stack traces aren't interesting
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	var mu sync.Mutex
+	got := map[int64][]int{}
+
+	d := newDispatcher(4, 2, func(u tgbotapi.Update) {
+		if u.UpdateID%7 == 0 {
+			panic("bad update")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		chatID := u.Message.Chat.ID
+		got[chatID] = append(got[chatID], u.UpdateID)
+	})
+
+	for i := 1; i <= 100; i++ {
+		d.Dispatch(chatUpdate(i, int64(i%3)))
+	}
+	d.Close()
+	d.Wait()
+
+	processed := 0
+	for chatID, ids := range got {
+		processed += len(ids)
+		for i := 1; i < len(ids); i++ {
+			if ids[i-1] >= ids[i] {
+				t.Errorf("chat %d updates processed out of order: %v", chatID, ids)
+				break
+			}
+		}
+	}
+
+	if want := 100 - 100/7; processed != want {
+		t.Errorf("processed %d updates, want %d", processed, want)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/rpeshkov/slovnik"
+)
+
+const (
+	// Number of words that are looked up at the same time
+	glossaryConcurrency = 4
+
+	// Number of translations shown for each word of glossary
+	glossaryTranslations = 3
+
+	// Number of expand buttons in one keyboard row
+	glossaryButtonsPerRow = 3
+)
+
+// glossaryEntry is a short translation of one word of the input
+type glossaryEntry struct {
+	Token string
+
+	// Word is the headword found for the token. It's empty when nothing is found.
+	Word         string
+	Translations []string
+
+	// Guess is set when token wasn't found and Word is one of suggested words
+	Guess bool
+}
+
+// glossary is a set of short translations of all words of the input
+type glossary struct {
+	Entries []glossaryEntry
+
+	// Skipped is a number of words that weren't looked up because of the limit
+	Skipped int
+}
+
+// buildGlossary looks up tokens concurrently and returns entries in the order of tokens.
+// Language of each token is chosen by settings. Failed lookups are logged to logger.
+func buildGlossary(ctx context.Context, translator slovnik.Translator, logger *slog.Logger, tokens []string, limit int, settings ChatSettings) glossary {
+	g := glossary{}
+	if len(tokens) > limit {
+		g.Skipped = len(tokens) - limit
+		tokens = tokens[:limit]
+	}
+
+	g.Entries = make([]glossaryEntry, len(tokens))
+	sem := make(chan struct{}, glossaryConcurrency)
+	var wg sync.WaitGroup
+
+	for i, token := range tokens {
+		wg.Add(1)
+		go func(i int, token string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			g.Entries[i] = lookupEntry(ctx, translator, logger, token, settings.Language(token))
+		}(i, token)
+	}
+	wg.Wait()
+
+	return g
+}
+
+// lookupEntry translates single token. Errors are logged and token is shown as not found.
+func lookupEntry(ctx context.Context, translator slovnik.Translator, logger *slog.Logger, token string, language slovnik.Language) glossaryEntry {
+	e := glossaryEntry{Token: token}
+
+	words, err := translator.Translate(ctx, token, language)
+	if err != nil {
+		logger.Warn("glossary lookup failed", "word", token, "direction", language.String(), "err", err)
+		return e
+	}
+
+	if len(words) == 0 {
+		return e
+	}
+
+	e.Word = words[0].Word
+	e.Guess = !slovnik.IsEntry(words, token)
+	e.Translations = words[0].Translations
+	if len(e.Translations) > glossaryTranslations {
+		e.Translations = e.Translations[:glossaryTranslations]
+	}
+
+	return e
+}
+
+// handleGlossary replies with short translations of every word of the input
+func (bot *Bot) handleGlossary(ctx context.Context, chatID int64, tokens []string, note string) {
+	g := buildGlossary(ctx, bot.translator, bot.logger, tokens, bot.maxTokens, bot.chatSettings(chatID))
+
+	msg := tgbotapi.NewMessage(chatID, note+bot.templates.Glossary(g))
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	if keyboard := bot.glossaryKeyboard(g); keyboard != nil {
+		msg.ReplyMarkup = keyboard
+	}
+
+	if _, err := bot.api.Send(msg); err != nil {
+		bot.logger.Error("unable to send glossary", "chat_id", chatID, "err", err)
+	}
+}
+
+// glossaryKeyboard creates a button for each found word that shows its full translation.
+// Forms of the same word get one button.
+func (bot *Bot) glossaryKeyboard(g glossary) *tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	row := []tgbotapi.InlineKeyboardButton{}
+	seen := map[string]bool{}
+
+	for _, e := range g.Entries {
+		if e.Word == "" || seen[e.Word] {
+			continue
+		}
+		seen[e.Word] = true
+
+		data := callbackData{Action: actionExpand, Token: bot.tokens.Token(e.Word)}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(buttonText(e.Word), data.String()))
+
+		if len(row) == glossaryButtonsPerRow {
+			rows = append(rows, row)
+			row = []tgbotapi.InlineKeyboardButton{}
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &keyboard
+}
+
+// expandWord sends full translation of the word selected in glossary
+func (bot *Bot) expandWord(ctx context.Context, query *tgbotapi.CallbackQuery, token string) {
+	chatID := query.Message.Chat.ID
+
+	w, ok := bot.tokens.Word(token)
+	if !ok {
+		bot.answerCallback(query.ID, "Кнопка устарела, повторите запрос")
+		return
+	}
+
+	bot.answerCallback(query.ID, "")
+	bot.sendTranslation(ctx, chatID, w, "")
+}
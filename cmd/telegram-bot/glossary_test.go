@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/rpeshkov/slovnik"
+)
+
+func TestBuildGlossary(t *testing.T) {
+	translator := stubTranslator{
+		"psi":   {{Word: "pes", WordType: "m", Translations: []string{"собака", "пёс", "псина", "кобель"}}},
+		"psa":   {{Word: "pes", WordType: "m", Translations: []string{"собака"}}},
+		"kocak": {{Word: "kočka", Translations: []string{"кошка"}}},
+		"kocka": {
+			{Word: "kočka", Translations: []string{"кошка"}},
+			{Word: "kočár", Translations: []string{"карета"}},
+		},
+	}
+
+	cases := []struct {
+		tokens  []string
+		limit   int
+		want    []glossaryEntry
+		skipped int
+	}{
+		{
+			[]string{"psi", "kocka", "kocak", "xyz"},
+			10,
+			[]glossaryEntry{
+				{Token: "psi", Word: "pes", Translations: []string{"собака", "пёс", "псина"}},
+				{Token: "kocka", Word: "kočka", Translations: []string{"кошка"}, Guess: true},
+				{Token: "kocak", Word: "kočka", Translations: []string{"кошка"}, Guess: true},
+				{Token: "xyz"},
+			},
+			0,
+		},
+		{
+			[]string{"psi", "psa", "kocka", "xyz"},
+			2,
+			[]glossaryEntry{
+				{Token: "psi", Word: "pes", Translations: []string{"собака", "пёс", "псина"}},
+				{Token: "psa", Word: "pes", Translations: []string{"собака"}},
+			},
+			2,
+		},
+	}
+
+	for _, c := range cases {
+		g := buildGlossary(context.Background(), translator, slog.Default(), c.tokens, c.limit, ChatSettings{})
+		if !reflect.DeepEqual(g.Entries, c.want) || g.Skipped != c.skipped {
+			t.Errorf("buildGlossary(%q, %d) == %+v, skipped %d, want %+v, skipped %d",
+				c.tokens, c.limit, g.Entries, g.Skipped, c.want, c.skipped)
+		}
+	}
+}
+
+func TestGlossaryKeyboard(t *testing.T) {
+	bot, _ := newTestBot(t, stubTranslator{})
+
+	g := glossary{Entries: []glossaryEntry{
+		{Token: "psi", Word: "pes"},
+		{Token: "xyz"},
+		{Token: "psa", Word: "pes"},
+		{Token: "kočky", Word: "kočka"},
+	}}
+
+	keyboard := bot.glossaryKeyboard(g)
+	if keyboard == nil {
+		t.Fatal("glossaryKeyboard() == nil, want buttons of found words")
+	}
+
+	var got []string
+	for _, row := range keyboard.InlineKeyboard {
+		for _, b := range row {
+			got = append(got, b.Text)
+		}
+	}
+	if want := []string{"pes", "kočka"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("glossaryKeyboard() buttons == %q, want %q", got, want)
+	}
+
+	if keyboard := bot.glossaryKeyboard(glossary{Entries: []glossaryEntry{{Token: "xyz"}}}); keyboard != nil {
+		t.Errorf("glossaryKeyboard() without found words == %v, want nil", keyboard)
+	}
+}
+
+func TestTranslateTextSingleToken(t *testing.T) {
+	bot, telegram := newTestBot(t, stubTranslator{"pes": {{Word: "pes", Translations: []string{"собака"}}}})
+
+	bot.translateText(context.Background(), 1, "pes!", "")
+
+	calls := telegram.Calls("sendMessage")
+	if len(calls) != 1 {
+		t.Fatalf("sendMessage is called %d times, want 1", len(calls))
+	}
+	want := bot.templates.Translation([]*slovnik.Word{{Word: "pes", Translations: []string{"собака"}}})
+	if text := calls[0].Get("text"); text != want {
+		t.Errorf("translation of %q == %q, want %q", "pes!", text, want)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// isGroup reports whether the chat is a group where the bot shouldn't answer every message
+func isGroup(chat *tgbotapi.Chat) bool {
+	return chat != nil && (chat.IsGroup() || chat.IsSuperGroup())
+}
+
+// groupRequest returns the text that the bot should process and false if the message
+// isn't addressed to the bot. Private chats get every message. In groups the bot reacts to
+// its own commands, mentions and replies to its messages. The mention is removed from the
+// text and a mention without text in reply to another message refers to that message.
+func groupRequest(message *tgbotapi.Message, self tgbotapi.User) (string, bool) {
+	if !isGroup(message.Chat) {
+		return message.Text, true
+	}
+
+	if message.IsCommand() {
+		command := message.CommandWithAt()
+		at := strings.Index(command, "@")
+		return message.Text, at < 0 || strings.EqualFold(command[at+1:], self.UserName)
+	}
+
+	if reply := message.ReplyToMessage; reply != nil && reply.From != nil && reply.From.ID == self.ID {
+		return message.Text, true
+	}
+
+	text, ok := removeMention(message, self.UserName)
+	if !ok {
+		return "", false
+	}
+
+	if text == "" && message.ReplyToMessage != nil {
+		text = message.ReplyToMessage.Text
+	}
+	return text, true
+}
+
+// removeMention returns message text without mentions of the user. It returns false
+// when the user isn't mentioned.
+func removeMention(message *tgbotapi.Message, username string) (string, bool) {
+	if message.Entities == nil || username == "" {
+		return "", false
+	}
+
+	// Entity offsets are measured in UTF-16 code units
+	text := utf16.Encode([]rune(message.Text))
+	rest := []uint16{}
+	pos := 0
+	found := false
+
+	for _, e := range *message.Entities {
+		if e.Type != "mention" || e.Offset < pos || e.Offset+e.Length > len(text) {
+			continue
+		}
+
+		mention := string(utf16.Decode(text[e.Offset : e.Offset+e.Length]))
+		if !strings.EqualFold(mention, "@"+username) {
+			continue
+		}
+
+		rest = append(rest, text[pos:e.Offset]...)
+		pos = e.Offset + e.Length
+		found = true
+	}
+
+	if !found {
+		return "", false
+	}
+
+	rest = append(rest, text[pos:]...)
+	return strings.Join(strings.Fields(string(utf16.Decode(rest))), " "), true
+}
+
+// handleTranslate translates the word from command arguments, it's the way to ask
+// for translation in groups
+func (bot *Bot) handleTranslate(ctx context.Context, message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	text := strings.TrimSpace(message.CommandArguments())
+
+	if text == "" {
+		bot.respond(chatID, "Напишите слово после команды, например: /t hlavní")
+		return
+	}
+
+	if err := validateInput(text, bot.maxInputLength); err != nil {
+		bot.respondError(chatID, err.Error())
+		return
+	}
+
+	bot.translateText(ctx, chatID, text, "")
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/rpeshkov/slovnik"
+)
+
+func TestGroupRequest(t *testing.T) {
+	self := tgbotapi.User{ID: 42, UserName: "SlovnikBot"}
+	group := &tgbotapi.Chat{ID: -1, Type: "supergroup"}
+	private := &tgbotapi.Chat{ID: 1, Type: "private"}
+
+	mention := func(offset, length int) *[]tgbotapi.MessageEntity {
+		return &[]tgbotapi.MessageEntity{{Type: "mention", Offset: offset, Length: length}}
+	}
+	command := func(length int) *[]tgbotapi.MessageEntity {
+		return &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: length}}
+	}
+
+	cases := []struct {
+		name    string
+		message tgbotapi.Message
+		text    string
+		ok      bool
+	}{
+		{"private", tgbotapi.Message{Chat: private, Text: "hlavní"}, "hlavní", true},
+		{"group", tgbotapi.Message{Chat: group, Text: "hlavní"}, "", false},
+		{"command", tgbotapi.Message{Chat: group, Text: "/t hlavní", Entities: command(2)}, "/t hlavní", true},
+		{"own command", tgbotapi.Message{Chat: group, Text: "/t@slovnikbot hlavní", Entities: command(13)}, "/t@slovnikbot hlavní", true},
+		{"other bot", tgbotapi.Message{Chat: group, Text: "/help@OtherBot", Entities: command(14)}, "/help@OtherBot", false},
+		{"mention", tgbotapi.Message{Chat: group, Text: "@SlovnikBot hlavní", Entities: mention(0, 11)}, "hlavní", true},
+		{"mention after text", tgbotapi.Message{Chat: group, Text: "столица @SlovnikBot", Entities: mention(8, 11)}, "столица", true},
+		{"other mention", tgbotapi.Message{Chat: group, Text: "@someone hlavní", Entities: mention(0, 8)}, "", false},
+		{
+			"reply to bot",
+			tgbotapi.Message{Chat: group, Text: "hlavní", ReplyToMessage: &tgbotapi.Message{From: &self}},
+			"hlavní", true,
+		},
+		{
+			"reply to user",
+			tgbotapi.Message{Chat: group, Text: "hlavní", ReplyToMessage: &tgbotapi.Message{From: &tgbotapi.User{ID: 7}}},
+			"", false,
+		},
+		{
+			"mention in reply",
+			tgbotapi.Message{Chat: group, Text: "@SlovnikBot", Entities: mention(0, 11), ReplyToMessage: &tgbotapi.Message{Text: "hlavní"}},
+			"hlavní", true,
+		},
+	}
+
+	for _, c := range cases {
+		text, ok := groupRequest(&c.message, self)
+		if text != c.text || ok != c.ok {
+			t.Errorf("groupRequest(%s) == %q, %v, want %q, %v", c.name, text, ok, c.text, c.ok)
+		}
+	}
+}
+
+func TestChatSettingsLanguage(t *testing.T) {
+	cases := []struct {
+		direction string
+		text      string
+		lang      slovnik.Language
+	}{
+		{"", "hlavní", slovnik.Cz},
+		{"", "столица", slovnik.Ru},
+		{"ru", "hlavní", slovnik.Ru},
+		{"cz", "столица", slovnik.Cz},
+	}
+
+	for _, c := range cases {
+		s := ChatSettings{Direction: c.direction}
+		if got := s.Language(c.text); got != c.lang {
+			t.Errorf("ChatSettings{Direction: %q}.Language(%q) == %v, want %v", c.direction, c.text, got, c.lang)
+		}
+	}
+}
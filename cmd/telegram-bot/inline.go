@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/rpeshkov/slovnik"
+	"github.com/rpeshkov/slovnik/metrics"
+)
+
+const (
+	// Telegram sends new inline query on every keystroke. Query is processed only if user
+	// doesn't change it during this period.
+	inlineDebounce = 400 * time.Millisecond
+
+	// inlineCacheTTL defines how long translations for inline queries are kept
+	inlineCacheTTL = 5 * time.Minute
+
+	// Queries shorter than this are ignored
+	inlineMinQueryLen = 2
+
+	// Telegram accepts not more than 50 results for inline query
+	inlineMaxResults = 50
+)
+
+// debouncer delays calls and drops the ones that are replaced by a newer call of the
+// same user during the delay
+type debouncer struct {
+	mu      sync.Mutex
+	delay   time.Duration
+	pending map[int]*time.Timer
+}
+
+func newDebouncer(delay time.Duration) *debouncer {
+	return &debouncer{
+		delay:   delay,
+		pending: make(map[int]*time.Timer),
+	}
+}
+
+// Debounce calls f after the delay unless the user makes another call before that. It
+// reports whether the pending call of the user is replaced and won't happen.
+func (d *debouncer) Debounce(userID int, f func()) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	replaced := false
+	if t, ok := d.pending[userID]; ok {
+		replaced = t.Stop()
+	}
+
+	// The timer can't fire before it's stored, since its function takes the lock first
+	var t *time.Timer
+	t = time.AfterFunc(d.delay, func() {
+		d.mu.Lock()
+		if d.pending[userID] == t {
+			delete(d.pending, userID)
+		}
+		d.mu.Unlock()
+		f()
+	})
+	d.pending[userID] = t
+
+	return replaced
+}
+
+type cacheEntry struct {
+	words   []*slovnik.Word
+	expires time.Time
+}
+
+// translationCache keeps translation results for a limited time
+type translationCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+func newTranslationCache(ttl time.Duration) *translationCache {
+	return &translationCache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// Get returns cached translation of the word
+func (c *translationCache) Get(word string) ([]*slovnik.Word, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[word]
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(e.expires) {
+		delete(c.entries, word)
+		return nil, false
+	}
+
+	return e.words, true
+}
+
+// Put stores translation of the word. Expired entries are removed on the way.
+func (c *translationCache) Put(word string, words []*slovnik.Word) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[word] = cacheEntry{words, now.Add(c.ttl)}
+}
+
+// handleInlineQuery answers inline query with the list of translations
+func (bot *Bot) handleInlineQuery(ctx context.Context, update *tgbotapi.Update) {
+	query := update.InlineQuery
+	text := strings.TrimSpace(query.Query)
+
+	if len([]rune(text)) < inlineMinQueryLen || validateInput(text, bot.maxInputLength) != nil {
+		return
+	}
+
+	// Rate limits are checked after debouncing, so only queries that are going to be
+	// translated are counted
+	if !bot.withinLimits(*update) {
+		return
+	}
+
+	words, ok := bot.inlineCache.Get(text)
+	metrics.CacheLookup("inline", ok)
+	if !ok {
+		var err error
+		words, err = bot.translator.Translate(ctx, text, slovnik.DetectLanguage(text))
+		if err != nil {
+			bot.logger.Error("translation failed", "user_id", query.From.ID, "word", text, "err", err)
+			return
+		}
+		bot.inlineCache.Put(text, words)
+	}
+
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       bot.inlineResults(words, text),
+		CacheTime:     int(inlineCacheTTL.Seconds()),
+	}
+
+	if _, err := bot.api.AnswerInlineQuery(answer); err != nil {
+		bot.logger.Error("unable to answer inline query", "user_id", query.From.ID, "err", err)
+	}
+}
+
+// inlineResults creates article for each translation of the word found by the query. When
+// mistype suggestions are found instead, article is created for each of them.
+func (bot *Bot) inlineResults(words []*slovnik.Word, query string) []interface{} {
+	results := []interface{}{}
+
+	if slovnik.IsEntry(words, query) {
+		w := words[0]
+		content := bot.templates.Translation(words)
+
+		for i, t := range w.Translations {
+			id := fmt.Sprintf("%s:%d", bot.tokens.Token(w.Word), i)
+			article := tgbotapi.NewInlineQueryResultArticleMarkdown(id, t, content)
+			article.Description = w.Word
+			results = append(results, article)
+		}
+	} else {
+		for _, w := range words {
+			id := bot.tokens.Token(w.Word)
+			content := bot.templates.Short([]*slovnik.Word{w})
+			article := tgbotapi.NewInlineQueryResultArticleMarkdown(id, w.Word, content)
+			article.Description = strings.Join(w.Translations, ", ")
+			results = append(results, article)
+		}
+	}
+
+	if len(results) > inlineMaxResults {
+		results = results[:inlineMaxResults]
+	}
+
+	return results
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/rpeshkov/slovnik"
+)
+
+func TestDebouncer(t *testing.T) {
+	d := newDebouncer(50 * time.Millisecond)
+
+	var (
+		mu    sync.Mutex
+		calls []string
+		wg    sync.WaitGroup
+	)
+	call := func(userID int, query string) bool {
+		wg.Add(1)
+		replaced := d.Debounce(userID, func() {
+			defer wg.Done()
+			mu.Lock()
+			calls = append(calls, query)
+			mu.Unlock()
+		})
+		if replaced {
+			wg.Done()
+		}
+		return replaced
+	}
+
+	cases := []struct {
+		userID   int
+		query    string
+		replaced bool
+	}{
+		{1, "hl", false},
+		{1, "hla", true},
+		{2, "pes", false},
+		{1, "hlav", true},
+	}
+	for _, c := range cases {
+		if got := call(c.userID, c.query); got != c.replaced {
+			t.Errorf("Debounce(%d, %q) == %v, want %v", c.userID, c.query, got, c.replaced)
+		}
+	}
+	wg.Wait()
+
+	if len(calls) != 2 || !(calls[0] == "hlav" || calls[1] == "hlav") {
+		t.Errorf("calls == %q, want only the latest query of each user", calls)
+	}
+
+	// Call that is already made isn't replaced
+	if call(1, "hlavní") {
+		t.Errorf("Debounce() replaced the call that is already made")
+	}
+	wg.Wait()
+}
+
+func TestHandleInlineSlots(t *testing.T) {
+	bot, telegram := newTestBot(t, stubTranslator{"pes": {{Word: "pes", Translations: []string{"собака"}}}})
+	bot.inlineSlots = make(chan struct{}, 1)
+
+	update := tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{ID: "1", From: &tgbotapi.User{ID: 7}, Query: "pes"}}
+
+	bot.inlineSlots <- struct{}{}
+	bot.handleInline(update)
+	if calls := telegram.Calls("answerInlineQuery"); len(calls) != 0 {
+		t.Errorf("answerInlineQuery is called %d times while all slots are busy, want 0", len(calls))
+	}
+
+	<-bot.inlineSlots
+	bot.handleInline(update)
+	if calls := telegram.Calls("answerInlineQuery"); len(calls) != 1 {
+		t.Errorf("answerInlineQuery is called %d times, want 1", len(calls))
+	}
+	if len(bot.inlineSlots) != 0 {
+		t.Errorf("slot isn't released after the query is answered")
+	}
+}
+
+func TestTranslationCache(t *testing.T) {
+	cache := newTranslationCache(time.Hour)
+	pes := []*slovnik.Word{{Word: "pes"}}
+	cache.Put("pes", pes)
+	cache.Put("nic", nil)
+	cache.entries["stary"] = cacheEntry{[]*slovnik.Word{{Word: "starý"}}, time.Now().Add(-time.Second)}
+
+	cases := []struct {
+		word string
+		want []*slovnik.Word
+		ok   bool
+	}{
+		{"pes", pes, true},
+		{"nic", nil, true},
+		{"stary", nil, false},
+		{"kocka", nil, false},
+	}
+	for _, c := range cases {
+		words, ok := cache.Get(c.word)
+		if ok != c.ok || len(words) != len(c.want) {
+			t.Errorf("Get(%q) == %v, %v, want %v, %v", c.word, words, ok, c.want, c.ok)
+		}
+	}
+
+	if _, ok := cache.entries["stary"]; ok {
+		t.Errorf("expired entry is kept after Get")
+	}
+
+	// Expired entries are removed by Put as well
+	cache.entries["stary"] = cacheEntry{nil, time.Now().Add(-time.Second)}
+	cache.Put("kocka", nil)
+	if len(cache.entries) != 3 {
+		t.Errorf("cache has %d entries after Put, want 3", len(cache.entries))
+	}
+}
+
+func TestInlineResults(t *testing.T) {
+	bot, _ := newTestBot(t, stubTranslator{})
+
+	many := make([]string, inlineMaxResults+10)
+	for i := range many {
+		many[i] = fmt.Sprintf("překlad %d", i)
+	}
+
+	cases := []struct {
+		words        []*slovnik.Word
+		query        string
+		titles       []string
+		descriptions []string
+	}{
+		{nil, "xyz", nil, nil},
+		{
+			[]*slovnik.Word{{Word: "pes", Translations: []string{"собака", "пёс"}}},
+			"pes",
+			[]string{"собака", "пёс"},
+			[]string{"pes", "pes"},
+		},
+		// Single suggestion is offered as a word, not as translations of the query
+		{
+			[]*slovnik.Word{{Word: "kočka", Translations: []string{"кошка", "киска"}}},
+			"kocak",
+			[]string{"kočka"},
+			[]string{"кошка, киска"},
+		},
+		{
+			[]*slovnik.Word{
+				{Word: "kočka", Translations: []string{"кошка", "киска"}},
+				{Word: "kočár", Translations: []string{"карета"}},
+			},
+			"koc",
+			[]string{"kočka", "kočár"},
+			[]string{"кошка, киска", "карета"},
+		},
+		{
+			[]*slovnik.Word{{Word: "slovo", Translations: many}},
+			"slovo",
+			many[:inlineMaxResults],
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		results := bot.inlineResults(c.words, c.query)
+		if len(results) != len(c.titles) {
+			t.Errorf("inlineResults(%q) returns %d results, want %d", c.query, len(results), len(c.titles))
+			continue
+		}
+
+		ids := map[string]bool{}
+		for i, r := range results {
+			article := r.(tgbotapi.InlineQueryResultArticle)
+			if article.Title != c.titles[i] {
+				t.Errorf("result #%d title == %q, want %q", i, article.Title, c.titles[i])
+			}
+			if c.descriptions != nil && article.Description != c.descriptions[i] {
+				t.Errorf("result #%d description == %q, want %q", i, article.Description, c.descriptions[i])
+			}
+			if ids[article.ID] {
+				t.Errorf("result #%d ID %q is not unique", i, article.ID)
+			}
+			ids[article.ID] = true
+
+			content := article.InputMessageContent.(tgbotapi.InputTextMessageContent)
+			if !strings.Contains(content.Text, c.words[0].Word) && !strings.Contains(content.Text, c.titles[i]) {
+				t.Errorf("result #%d content %q doesn't mention the word", i, content.Text)
+			}
+		}
+	}
+}
@@ -1,27 +1,63 @@
 package main
 
+//go:generate sh -c "go run . -env-template > ../../slovnik-vars.env"
+
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rpeshkov/slovnik/config"
 )
 
 func main() {
-	config, err := InitConfig()
+	cfg, err := LoadConfig(os.Args[1:])
+	if err == config.ErrExit {
+		return
+	}
 
 	if err != nil {
 		log.Panic(err)
 	}
 
-	templates, err := CreateTemplate()
+	logger, err := cfg.Logger(os.Stderr)
 	if err != nil {
 		log.Panic(err)
 	}
 
-	bot, err := NewBot(config, templates)
+	shutdownTracing, err := cfg.Tracer("telegram-bot")
+	if err != nil {
+		logger.Error("unable to set up tracing", "err", err)
+		os.Exit(1)
+	}
 
+	templates, err := CreateTemplate()
 	if err != nil {
-		log.Panic(err)
+		logger.Error("unable to load templates", "err", err)
+		os.Exit(1)
 	}
 
-	bot.Listen()
+	bot, err := NewBot(cfg, templates, logger)
 
+	if err != nil {
+		logger.Error("unable to start bot", "err", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = bot.Listen(ctx)
+
+	// Spans of the last updates are flushed even when bot stopped with error
+	if e := shutdownTracing(context.Background()); e != nil {
+		logger.Warn("unable to flush traces", "err", e)
+	}
+
+	if err != nil {
+		logger.Error("bot stopped", "err", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/rpeshkov/slovnik"
+)
+
+// Number of keyword filter buttons in one keyboard row
+const keywordsPerRow = 3
+
+// noKeyword is passed as keyword index when phrases aren't filtered
+const noKeyword = -1
+
+// phrasesPage is a single page of word samples shown to the user
+type phrasesPage struct {
+	Word    string
+	Keyword string
+	Samples []slovnik.SampleUse
+
+	// Page is a zero-based index of current page
+	Page  int
+	Pages int
+}
+
+// sampleKeywords returns distinct keywords of samples in order of appearance
+func sampleKeywords(samples []slovnik.SampleUse) []string {
+	seen := map[string]bool{}
+	keywords := []string{}
+	for _, s := range samples {
+		if s.Keyword == "" || seen[s.Keyword] {
+			continue
+		}
+		seen[s.Keyword] = true
+		keywords = append(keywords, s.Keyword)
+	}
+	return keywords
+}
+
+// paginatePhrases returns requested page of samples of the word. If keyword is not empty,
+// only samples with this keyword are taken into account. Page is clamped to available range.
+func paginatePhrases(w *slovnik.Word, keyword string, page int, size int) phrasesPage {
+	samples := w.Samples
+	if keyword != "" {
+		samples = []slovnik.SampleUse{}
+		for _, s := range w.Samples {
+			if s.Keyword == keyword {
+				samples = append(samples, s)
+			}
+		}
+	}
+
+	pages := (len(samples) + size - 1) / size
+	if pages == 0 {
+		pages = 1
+	}
+
+	if page >= pages {
+		page = pages - 1
+	}
+	if page < 0 {
+		page = 0
+	}
+
+	start := page * size
+	end := start + size
+	if end > len(samples) {
+		end = len(samples)
+	}
+
+	return phrasesPage{
+		Word:    w.Word,
+		Keyword: keyword,
+		Samples: samples[start:end],
+		Page:    page,
+		Pages:   pages,
+	}
+}
+
+// phrasesKeyboard creates keyboard with keyword filters and page navigation.
+// nil is returned when there's nothing to navigate.
+func phrasesKeyboard(token string, keywords []string, keywordIdx int, p phrasesPage) *tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+
+	button := func(text string, page int, kw int) tgbotapi.InlineKeyboardButton {
+		data := callbackData{Action: actionPhrases, Token: token, Args: []int{page, kw}}
+		return tgbotapi.NewInlineKeyboardButtonData(text, data.String())
+	}
+
+	if len(keywords) > 1 {
+		row := []tgbotapi.InlineKeyboardButton{}
+		for i, k := range keywords {
+			text := k
+			if i == keywordIdx {
+				text = "✓ " + k
+			}
+			row = append(row, button(text, 0, i))
+
+			if len(row) == keywordsPerRow {
+				rows = append(rows, row)
+				row = []tgbotapi.InlineKeyboardButton{}
+			}
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+
+		if keywordIdx != noKeyword {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(button("Все фразы", 0, noKeyword)))
+		}
+	}
+
+	if p.Pages > 1 {
+		nav := []tgbotapi.InlineKeyboardButton{}
+		if p.Page > 0 {
+			nav = append(nav, button("«", p.Page-1, keywordIdx))
+		}
+		counter := strconv.Itoa(p.Page+1) + "/" + strconv.Itoa(p.Pages)
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(counter, actionNoop))
+		if p.Page < p.Pages-1 {
+			nav = append(nav, button("»", p.Page+1, keywordIdx))
+		}
+		rows = append(rows, nav)
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &keyboard
+}
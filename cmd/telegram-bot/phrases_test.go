@@ -0,0 +1,120 @@
+package main
+
+import (
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/rpeshkov/slovnik"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestPaginatePhrases(t *testing.T) {
+	w := &slovnik.Word{
+		Word: "hlavní",
+		Samples: []slovnik.SampleUse{
+			{Keyword: "hlavní", Phrase: "hlavní město"},
+			{Keyword: "hlavní", Phrase: "hlavní chod"},
+			{Keyword: "hlavně", Phrase: "hlavně zdraví"},
+			{Keyword: "hlavní", Phrase: "hlavní role"},
+		},
+	}
+
+	cases := []struct {
+		keyword string
+		page    int
+		count   int
+		pages   int
+		want    int
+	}{
+		{"", 0, 2, 2, 0},
+		{"", 1, 2, 2, 1},
+		{"", 5, 2, 2, 1},
+		{"", -1, 2, 2, 0},
+		{"hlavní", 0, 2, 2, 0},
+		{"hlavně", 1, 1, 1, 0},
+		{"neznámý", 0, 0, 1, 0},
+	}
+
+	for _, c := range cases {
+		p := paginatePhrases(w, c.keyword, c.page, 2)
+		if len(p.Samples) != c.count || p.Pages != c.pages || p.Page != c.want {
+			t.Errorf("paginatePhrases(%q, %d) == (%d samples, page %d/%d), want (%d samples, page %d/%d)",
+				c.keyword, c.page, len(p.Samples), p.Page, p.Pages, c.count, c.want, c.pages)
+		}
+	}
+}
+
+func openTestDatabase(t *testing.T) *bolt.DB {
+	db, err := openDatabase(filepath.Join(t.TempDir(), "slovnik.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestCallbackData(t *testing.T) {
+	tokens := newCallbackTokens(openTestDatabase(t), 1, slog.Default())
+	word := "nejneobhospodařovávatelnějšími přípravky ke konzervaci dřeva a stavebních konstrukcí"
+
+	d := callbackData{Action: actionPhrases, Token: tokens.Token(word), Args: []int{12, noKeyword}}
+	data := d.String()
+	if len(data) > maxCallbackDataLen {
+		t.Fatalf("len(%q) == %d, want <= %d", data, len(data), maxCallbackDataLen)
+	}
+
+	got, err := parseCallbackData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Action != d.Action || got.Arg(0, 0) != 12 || got.Arg(1, 0) != noKeyword {
+		t.Errorf("parseCallbackData(%q) == %+v, want %+v", data, got, d)
+	}
+
+	if w, ok := tokens.Word(got.Token); !ok || w != word {
+		t.Errorf("Word(%q) == %q, want %q", got.Token, w, word)
+	}
+
+	tokens.Token("jiné slovo")
+	if _, ok := tokens.Word(got.Token); ok {
+		t.Errorf("Word(%q) is still resolved after eviction", got.Token)
+	}
+}
+
+func TestCallbackTokens(t *testing.T) {
+	db := openTestDatabase(t)
+
+	// The first token of the word is taken by another one, e.g. after restart
+	err := db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(tokensBucket).Put([]byte(tokenCandidate("slovo", 0)), []byte("jiné"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := newCallbackTokens(db, 2, slog.Default())
+	token := tokens.Token("slovo")
+	if token == tokenCandidate("slovo", 0) {
+		t.Errorf("Token(slovo) == %q, want token that isn't taken", token)
+	}
+	if again := tokens.Token("slovo"); again != token {
+		t.Errorf("Token(slovo) == %q, then %q, want the same token", token, again)
+	}
+	tokens.Token("město")
+
+	// Tokens are resolved after restart
+	restarted := newCallbackTokens(db, 2, slog.Default())
+	if w, ok := restarted.Word(token); !ok || w != "slovo" {
+		t.Errorf("Word(%q) after restart == %q, %v, want slovo", token, w, ok)
+	}
+	if got := restarted.Token("slovo"); got != token {
+		t.Errorf("Token(slovo) after restart == %q, want %q", got, token)
+	}
+
+	// The oldest word is forgotten in the database as well
+	tokens.Token("řeka")
+	if w, ok := newCallbackTokens(db, 2, slog.Default()).Word(token); ok {
+		t.Errorf("Word(%q) == %q after eviction, want nothing", token, w)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/rpeshkov/slovnik"
+	bolt "go.etcd.io/bbolt"
+)
+
+// VoiceStore keeps telegram file IDs of generated pronunciations, so audio is generated
+// and uploaded only once for each word
+type VoiceStore interface {
+	// Get returns file ID of pronunciation or empty string if it wasn't generated yet
+	Get(word string, language slovnik.Language) (string, error)
+	Save(word string, language slovnik.Language, fileID string) error
+}
+
+// boltVoices is a VoiceStore that keeps file IDs in bolt database
+type boltVoices struct {
+	db *bolt.DB
+}
+
+// newBoltVoices creates voice store in the database opened by openDatabase
+func newBoltVoices(db *bolt.DB) *boltVoices {
+	return &boltVoices{db}
+}
+
+func voiceKey(word string, language slovnik.Language) []byte {
+	return []byte(strconv.Itoa(int(language)) + ":" + strings.ToLower(word))
+}
+
+// Get returns file ID of pronunciation
+func (b *boltVoices) Get(word string, language slovnik.Language) (string, error) {
+	fileID := ""
+	err := b.db.View(func(tx *bolt.Tx) error {
+		fileID = string(tx.Bucket(voicesBucket).Get(voiceKey(word, language)))
+		return nil
+	})
+	return fileID, err
+}
+
+// Save remembers file ID of pronunciation
+func (b *boltVoices) Save(word string, language slovnik.Language, fileID string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(voicesBucket).Put(voiceKey(word, language), []byte(fileID))
+	})
+}
+
+// pronunciation returns voice message with pronunciation of the word. Previously uploaded
+// audio is reused, otherwise audio is synthesized.
+func (bot *Bot) pronunciation(ctx context.Context, chatID int64, word string) (tgbotapi.VoiceConfig, error) {
+	language := slovnik.DetectLanguage(word)
+
+	fileID, err := bot.voices.Get(word, language)
+	if err != nil {
+		bot.logger.Warn("unable to get stored pronunciation", "chat_id", chatID, "word", word, "err", err)
+	}
+
+	var msg tgbotapi.VoiceConfig
+	if fileID != "" {
+		msg = tgbotapi.NewVoiceShare(chatID, fileID)
+	} else {
+		audio, err := bot.synthesizer.Synthesize(ctx, word, language)
+		if err != nil {
+			return msg, err
+		}
+		msg = tgbotapi.NewVoiceUpload(chatID, tgbotapi.FileBytes{Name: "pronunciation.ogg", Bytes: audio})
+	}
+
+	msg.Caption = word
+	return msg, nil
+}
+
+// sendPronunciation sends voice message with pronunciation of the word
+func (bot *Bot) sendPronunciation(ctx context.Context, chatID int64, word string) {
+	if bot.synthesizer == nil {
+		bot.respond(chatID, "Произношение недоступно")
+		return
+	}
+
+	msg, err := bot.pronunciation(ctx, chatID, word)
+	if err != nil {
+		bot.respondError(chatID, "Не удалось озвучить слово :(")
+		bot.logger.Error("unable to synthesize pronunciation", "chat_id", chatID, "word", word, "err", err)
+		return
+	}
+
+	sent, err := bot.api.Send(msg)
+	if err != nil {
+		bot.logger.Error("unable to send pronunciation", "chat_id", chatID, "word", word, "err", err)
+		return
+	}
+
+	if msg.UseExisting || sent.Voice == nil {
+		return
+	}
+
+	if err = bot.voices.Save(word, slovnik.DetectLanguage(word), sent.Voice.FileID); err != nil {
+		bot.logger.Error("unable to store pronunciation", "word", word, "err", err)
+	}
+}
+
+// handleSay pronounces the word passed as command argument
+func (bot *Bot) handleSay(ctx context.Context, message *tgbotapi.Message) {
+	word := strings.TrimSpace(message.CommandArguments())
+	if word == "" {
+		bot.respond(message.Chat.ID, "Укажите слово: /say hlavní")
+		return
+	}
+
+	if err := validateInput(word, bot.maxInputLength); err != nil {
+		bot.respondError(message.Chat.ID, err.Error())
+		return
+	}
+
+	bot.sendPronunciation(ctx, message.Chat.ID, word)
+}
+
+// sayWord pronounces the word from the button
+func (bot *Bot) sayWord(ctx context.Context, query *tgbotapi.CallbackQuery, token string) {
+	w, ok := bot.tokens.Word(token)
+	if !ok {
+		bot.answerCallback(query.ID, "Кнопка устарела, повторите запрос")
+		return
+	}
+
+	bot.answerCallback(query.ID, "")
+	bot.sendPronunciation(ctx, query.Message.Chat.ID, w)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/rpeshkov/slovnik"
+	"github.com/rpeshkov/slovnik/speech"
+)
+
+func TestPronunciation(t *testing.T) {
+	bot, _ := newTestBot(t, stubTranslator{})
+	synthesizer := &speech.FakeSynthesizer{Audio: []byte("OggS")}
+	bot.synthesizer = synthesizer
+
+	msg, err := bot.pronunciation(context.Background(), 1, "hlavní")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.UseExisting || synthesizer.Calls != 1 {
+		t.Fatalf("pronunciation() reused audio before it was uploaded")
+	}
+	if file, ok := msg.File.(tgbotapi.FileBytes); !ok || string(file.Bytes) != "OggS" {
+		t.Errorf("pronunciation() file == %v, want synthesized audio", msg.File)
+	}
+
+	if err = bot.voices.Save("hlavní", slovnik.Cz, "file-id"); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err = bot.pronunciation(context.Background(), 1, "Hlavní")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !msg.UseExisting || msg.FileID != "file-id" || synthesizer.Calls != 1 {
+		t.Errorf("pronunciation() == %+v, want uploaded file to be reused", msg.BaseFile)
+	}
+}
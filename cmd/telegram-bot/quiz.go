@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/rpeshkov/slovnik"
+)
+
+// quizSessions keeps the word each chat is asked to translate
+type quizSessions struct {
+	mu    sync.Mutex
+	words map[int64]string
+}
+
+func newQuizSessions() *quizSessions {
+	return &quizSessions{words: make(map[int64]string)}
+}
+
+// Start remembers the word the chat is asked about
+func (q *quizSessions) Start(chatID int64, word string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.words[chatID] = word
+}
+
+// Finish returns the word the chat is asked about and forgets it
+func (q *quizSessions) Finish(chatID int64) (string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	w, ok := q.words[chatID]
+	delete(q.words, chatID)
+	return w, ok
+}
+
+// FinishWord forgets the question only if the chat is asked about the word, so an old
+// question doesn't close the current one. It reports whether the question is finished.
+func (q *quizSessions) FinishWord(chatID int64, word string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if w, ok := q.words[chatID]; !ok || w != word {
+		return false
+	}
+	delete(q.words, chatID)
+	return true
+}
+
+// saveWord adds the word from the button to the chat vocabulary
+func (bot *Bot) saveWord(ctx context.Context, query *tgbotapi.CallbackQuery, token string) {
+	chatID := query.Message.Chat.ID
+
+	w, ok := bot.tokens.Word(token)
+	if !ok {
+		bot.answerCallback(query.ID, "Кнопка устарела, повторите запрос")
+		return
+	}
+
+	existing, err := bot.vocabulary.Get(chatID, w)
+	if err != nil {
+		bot.answerCallback(query.ID, "Не удалось сохранить слово :(")
+		bot.logger.Error("unable to get saved word", "chat_id", chatID, "word", w, "err", err)
+		return
+	}
+	if existing != nil {
+		bot.answerCallback(query.ID, "Слово уже в вашем списке")
+		return
+	}
+
+	words, err := bot.translator.Translate(ctx, w, bot.chatSettings(chatID).Language(w))
+	if err != nil {
+		bot.answerCallback(query.ID, "Не удалось сохранить слово :(")
+		bot.logger.Error("unable to translate saved word", "chat_id", chatID, "word", w, "err", err)
+		return
+	}
+	if !slovnik.IsEntry(words, w) {
+		bot.answerCallback(query.ID, "Не удалось сохранить слово :(")
+		bot.logger.Error("saved word isn't found in the dictionary", "chat_id", chatID, "word", w, "results", len(words))
+		return
+	}
+
+	if err = bot.vocabulary.Save(chatID, newVocabularyEntry(*words[0], time.Now())); err != nil {
+		bot.answerCallback(query.ID, "Не удалось сохранить слово :(")
+		bot.logger.Error("unable to save word", "chat_id", chatID, "word", w, "err", err)
+		return
+	}
+
+	bot.answerCallback(query.ID, "⭐ Слово сохранено")
+}
+
+// handleList sends the list of saved words
+func (bot *Bot) handleList(ctx context.Context, message *tgbotapi.Message) {
+	entries, err := bot.vocabulary.List(message.Chat.ID)
+	if err != nil {
+		bot.respondError(message.Chat.ID, "Something bad happened :(")
+		bot.logger.Error("unable to list saved words", "chat_id", message.Chat.ID, "err", err)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, bot.templates.Vocabulary(entries))
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	if _, err = bot.api.Send(msg); err != nil {
+		bot.logger.Error("unable to send saved words", "chat_id", message.Chat.ID, "err", err)
+	}
+}
+
+// handleExport sends saved words as CSV file
+func (bot *Bot) handleExport(ctx context.Context, message *tgbotapi.Message) {
+	entries, err := bot.vocabulary.List(message.Chat.ID)
+	if err != nil {
+		bot.respondError(message.Chat.ID, "Something bad happened :(")
+		bot.logger.Error("unable to list saved words", "chat_id", message.Chat.ID, "err", err)
+		return
+	}
+
+	if len(entries) == 0 {
+		bot.respond(message.Chat.ID, "Ваш список слов пуст")
+		return
+	}
+
+	var buf bytes.Buffer
+	out := csv.NewWriter(&buf)
+	out.Write([]string{"word", "translations", "type", "added", "due"})
+	for _, e := range entries {
+		out.Write([]string{
+			e.Word.Word,
+			strings.Join(e.Word.Translations, ", "),
+			e.Word.WordType,
+			e.Added.Format(time.RFC3339),
+			e.Due.Format(time.RFC3339),
+		})
+	}
+	out.Flush()
+
+	doc := tgbotapi.NewDocumentUpload(message.Chat.ID, tgbotapi.FileBytes{
+		Name:  "vocabulary.csv",
+		Bytes: buf.Bytes(),
+	})
+	if _, err = bot.api.Send(doc); err != nil {
+		bot.logger.Error("unable to send export", "chat_id", message.Chat.ID, "err", err)
+	}
+}
+
+// handleQuiz asks the user to translate the saved word that is due for repetition
+func (bot *Bot) handleQuiz(ctx context.Context, message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	entries, err := bot.vocabulary.List(chatID)
+	if err != nil {
+		bot.respondError(chatID, "Something bad happened :(")
+		bot.logger.Error("unable to list saved words", "chat_id", chatID, "err", err)
+		return
+	}
+
+	e := nextDue(entries)
+	if e == nil {
+		bot.respond(chatID, "Ваш список слов пуст. Сохраняйте слова кнопкой ⭐")
+		return
+	}
+
+	if e.Due.After(time.Now()) {
+		bot.respond(chatID, "Пока нечего повторять. Следующее слово — "+e.Due.Format("02.01.2006 15:04"))
+		return
+	}
+
+	bot.quizzes.Start(chatID, e.Word.Word)
+
+	reveal := callbackData{Action: actionReveal, Token: bot.tokens.Token(e.Word.Word)}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Не знаю", reveal.String()),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, "Переведите: *"+e.Word.Word+"*")
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = keyboard
+	if _, err = bot.api.Send(msg); err != nil {
+		bot.logger.Error("unable to send quiz question", "chat_id", chatID, "err", err)
+	}
+}
+
+// checkQuizAnswer grades the answer if the chat has unanswered quiz question. It returns
+// false if there's no question, so message should be processed as usual.
+func (bot *Bot) checkQuizAnswer(chatID int64, text string) bool {
+	w, ok := bot.quizzes.Finish(chatID)
+	if !ok {
+		return false
+	}
+
+	e, err := bot.vocabulary.Get(chatID, w)
+	if err != nil || e == nil {
+		bot.logger.Error("unable to get quiz word", "chat_id", chatID, "word", w, "err", err)
+		return false
+	}
+
+	quality := gradeAnswer(e.Word, text)
+	bot.review(chatID, e, quality)
+	return true
+}
+
+// revealAnswer shows the answer to the quiz question that the user doesn't know
+func (bot *Bot) revealAnswer(query *tgbotapi.CallbackQuery, token string) {
+	chatID := query.Message.Chat.ID
+
+	w, ok := bot.tokens.Word(token)
+	if !ok {
+		bot.answerCallback(query.ID, "Кнопка устарела, повторите запрос")
+		return
+	}
+
+	if !bot.quizzes.FinishWord(chatID, w) {
+		bot.answerCallback(query.ID, "Вопрос уже закрыт")
+		return
+	}
+
+	e, err := bot.vocabulary.Get(chatID, w)
+	if err != nil || e == nil {
+		bot.answerCallback(query.ID, "")
+		bot.logger.Error("unable to get quiz word", "chat_id", chatID, "word", w, "err", err)
+		return
+	}
+
+	bot.answerCallback(query.ID, "")
+	bot.review(chatID, e, qualityForgotten)
+}
+
+// review updates the schedule of the entry and tells the user the result
+func (bot *Bot) review(chatID int64, e *VocabularyEntry, quality int) {
+	e.Review(quality, time.Now())
+	if err := bot.vocabulary.Save(chatID, e); err != nil {
+		bot.logger.Error("unable to save review", "chat_id", chatID, "word", e.Word.Word, "err", err)
+	}
+
+	var verdict string
+	switch {
+	case quality == qualityPerfect:
+		verdict = "✅ Верно!"
+	case quality >= qualityPassing:
+		verdict = "✅ Верно, но обратите внимание на диакритику"
+	default:
+		verdict = "❌ Правильный ответ"
+	}
+
+	text := verdict + "\n\n" + bot.templates.Translation([]*slovnik.Word{&e.Word})
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	if _, err := bot.api.Send(msg); err != nil {
+		bot.logger.Error("unable to send review", "chat_id", chatID, "err", err)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/rpeshkov/slovnik"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Page sizes that can be selected in settings
+var phrasesPageSizes = []int{3, 5, 10}
+
+// translationDirection is a choice of the language of input words
+type translationDirection struct {
+	// Code is a language code, empty code means that language is detected
+	Code string
+	Name string
+}
+
+// Directions that can be selected in settings, button arguments refer to them by index
+var directions = []translationDirection{
+	{"", "Авто"},
+	{"cz", "🇨🇿 → 🇷🇺"},
+	{"ru", "🇷🇺 → 🇨🇿"},
+}
+
+// ChatSettings contains preferences of the chat
+type ChatSettings struct {
+	// PhrasesPageSize is a number of phrases shown on one page, zero means default
+	PhrasesPageSize int `json:"phrasesPageSize,omitempty"`
+
+	// Direction is a code of the language of input words, empty means that it's detected
+	Direction string `json:"direction,omitempty"`
+
+	// HidePhrases removes phrases button from translations, it's useful in busy groups
+	HidePhrases bool `json:"hidePhrases,omitempty"`
+}
+
+// Language returns the language used to translate the text
+func (s ChatSettings) Language(text string) slovnik.Language {
+	if lang, err := slovnik.ParseLanguage(s.Direction); err == nil {
+		return lang
+	}
+	return slovnik.DetectLanguage(text)
+}
+
+// DirectionName returns human readable translation direction
+func (s ChatSettings) DirectionName() string {
+	for _, d := range directions {
+		if d.Code == s.Direction {
+			return d.Name
+		}
+	}
+	return directions[0].Name
+}
+
+// SettingsStore keeps preferences of the chats
+type SettingsStore interface {
+	// Get returns settings of the chat. Chats that never changed settings get zero value.
+	Get(chatID int64) (ChatSettings, error)
+	Save(chatID int64, s ChatSettings) error
+}
+
+// boltSettings is a SettingsStore that keeps settings in bolt database
+type boltSettings struct {
+	db *bolt.DB
+}
+
+// newBoltSettings creates settings store in the database opened by openDatabase
+func newBoltSettings(db *bolt.DB) *boltSettings {
+	return &boltSettings{db}
+}
+
+// Get returns settings of the chat
+func (b *boltSettings) Get(chatID int64) (ChatSettings, error) {
+	s := ChatSettings{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(settingsBucket).Get(chatKey(chatID))
+		if data == nil {
+			return nil
+		}
+		return json.Unmarshal(data, &s)
+	})
+	return s, err
+}
+
+// Save replaces settings of the chat
+func (b *boltSettings) Save(chatID int64, s ChatSettings) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(settingsBucket).Put(chatKey(chatID), data)
+	})
+}
+
+// chatSettings returns settings of the chat with defaults applied
+func (bot *Bot) chatSettings(chatID int64) ChatSettings {
+	s, err := bot.settings.Get(chatID)
+	if err != nil {
+		bot.logger.Error("unable to get settings", "chat_id", chatID, "err", err)
+	}
+
+	if s.PhrasesPageSize <= 0 {
+		s.PhrasesPageSize = bot.phrasesPageSize
+	}
+	return s
+}
+
+// settingsKeyboard creates keyboard for changing chat settings
+func settingsKeyboard(s ChatSettings) tgbotapi.InlineKeyboardMarkup {
+	sizes := []tgbotapi.InlineKeyboardButton{}
+	for _, size := range phrasesPageSizes {
+		sizes = append(sizes, settingButton(strconv.Itoa(size), size == s.PhrasesPageSize, settingPageSize, size))
+	}
+
+	dirs := []tgbotapi.InlineKeyboardButton{}
+	for i, d := range directions {
+		dirs = append(dirs, settingButton(d.Name, d.Code == s.Direction, settingDirection, i))
+	}
+
+	phrases := []tgbotapi.InlineKeyboardButton{
+		settingButton("Фразы: показывать", !s.HidePhrases, settingPhrases, 1),
+		settingButton("скрывать", s.HidePhrases, settingPhrases, 0),
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(sizes, dirs, phrases)
+}
+
+// settingButton creates a button that sets the value of the setting. Current value is marked.
+func settingButton(text string, selected bool, setting int, value int) tgbotapi.InlineKeyboardButton {
+	if selected {
+		text = "✓ " + text
+	}
+	data := callbackData{Action: actionSettings, Args: []int{setting, value}}
+	return tgbotapi.NewInlineKeyboardButtonData(text, data.String())
+}
+
+// handleSettings shows settings of the chat
+func (bot *Bot) handleSettings(ctx context.Context, message *tgbotapi.Message) {
+	s := bot.chatSettings(message.Chat.ID)
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, bot.templates.Settings(s))
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = settingsKeyboard(s)
+	if _, err := bot.api.Send(msg); err != nil {
+		bot.logger.Error("unable to send settings", "chat_id", message.Chat.ID, "err", err)
+	}
+}
+
+// changeSetting applies the setting selected with the button
+func (bot *Bot) changeSetting(query *tgbotapi.CallbackQuery, data callbackData) {
+	chatID := query.Message.Chat.ID
+	s := bot.chatSettings(chatID)
+
+	switch data.Arg(0, -1) {
+	case settingPageSize:
+		size := data.Arg(1, 0)
+		if size <= 0 {
+			bot.answerCallback(query.ID, "")
+			return
+		}
+		s.PhrasesPageSize = size
+	case settingDirection:
+		i := data.Arg(1, -1)
+		if i < 0 || i >= len(directions) {
+			bot.answerCallback(query.ID, "")
+			return
+		}
+		s.Direction = directions[i].Code
+	case settingPhrases:
+		s.HidePhrases = data.Arg(1, 1) == 0
+	default:
+		bot.answerCallback(query.ID, "")
+		return
+	}
+
+	if err := bot.settings.Save(chatID, s); err != nil {
+		bot.answerCallback(query.ID, "Не удалось сохранить настройки :(")
+		bot.logger.Error("unable to save settings", "chat_id", chatID, "err", err)
+		return
+	}
+	bot.answerCallback(query.ID, "Настройки сохранены")
+
+	editMsg := tgbotapi.NewEditMessageText(chatID, query.Message.MessageID, bot.templates.Settings(s))
+	keyboard := settingsKeyboard(s)
+	editMsg.ReplyMarkup = &keyboard
+	editMsg.ParseMode = tgbotapi.ModeMarkdown
+	if _, err := bot.api.Send(editMsg); err != nil {
+		bot.logger.Error("unable to update settings", "chat_id", chatID, "err", err)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rpeshkov/slovnik"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestSlovnikClientPropagatesTrace(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	provider := sdktrace.NewTracerProvider()
+	defer provider.Shutdown(context.Background())
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	client, err := newSlovnikClient(server.URL+"/api", "", nil, slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "test")
+	defer span.End()
+
+	if _, err = client.Translate(ctx, "slovo", slovnik.Cz); err != nil {
+		t.Fatal(err)
+	}
+
+	traceID := span.SpanContext().TraceID().String()
+	if !strings.Contains(traceparent, traceID) {
+		t.Errorf("traceparent == %q, want trace %s", traceparent, traceID)
+	}
+}
+
+func TestSlovnikClientSendsAPIKey(t *testing.T) {
+	// Server rejects requests without the key like API server does when keys are configured
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "bot-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"API key is required"}`))
+			return
+		}
+		w.Write([]byte(`[{"word":"slovo","translations":["слово"]}]`))
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		key   string
+		valid bool
+	}{
+		{"bot-key", true},
+		{"", false},
+		{"other", false},
+	}
+
+	for _, c := range cases {
+		client, err := newSlovnikClient(server.URL+"/api", c.key, nil, slog.Default())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		words, err := client.Translate(context.Background(), "slovo", slovnik.Cz)
+		if (err == nil) != c.valid {
+			t.Errorf("Translate() with key %q error == %v, want valid %v", c.key, err, c.valid)
+			continue
+		}
+		if c.valid && (len(words) != 1 || words[0].Word != "slovo") {
+			t.Errorf("Translate() with key %q == %+v, want slovo", c.key, words)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/binary"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Top level buckets of bot database
+var (
+	vocabularyBucket = []byte("vocabulary")
+	blockedBucket    = []byte("blocked")
+	settingsBucket   = []byte("settings")
+	voicesBucket     = []byte("voices")
+	dailyBucket      = []byte("daily")
+	tokensBucket     = []byte("tokens")
+	tokenOrderBucket = []byte("token_order")
+)
+
+// openDatabase opens or creates database file at provided path and prepares all buckets
+func openDatabase(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open database")
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{vocabularyBucket, blockedBucket, settingsBucket, voicesBucket, dailyBucket, tokensBucket, tokenOrderBucket} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "unable to create buckets")
+	}
+
+	return db, nil
+}
+
+func chatKey(chatID int64) []byte {
+	return []byte(strconv.FormatInt(chatID, 10))
+}
+
+// BlockList keeps chats that the bot doesn't serve
+type BlockList interface {
+	Block(chatID int64) error
+	Unblock(chatID int64) error
+	IsBlocked(chatID int64) (bool, error)
+
+	// List returns all blocked chats
+	List() ([]int64, error)
+}
+
+// boltBlockList is a BlockList that keeps blocked chats in bolt database. The list is
+// checked for every update, so it's also kept in memory once loaded.
+type boltBlockList struct {
+	db *bolt.DB
+
+	mu      sync.RWMutex
+	blocked map[int64]bool
+}
+
+// newBoltBlockList creates block list in the database opened by openDatabase
+func newBoltBlockList(db *bolt.DB) *boltBlockList {
+	return &boltBlockList{db: db}
+}
+
+// Block adds chat to the list
+func (b *boltBlockList) Block(chatID int64) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		since := make([]byte, 8)
+		binary.BigEndian.PutUint64(since, uint64(time.Now().Unix()))
+		return tx.Bucket(blockedBucket).Put(chatKey(chatID), since)
+	})
+	if err == nil {
+		b.cache(chatID, true)
+	}
+	return err
+}
+
+// Unblock removes chat from the list
+func (b *boltBlockList) Unblock(chatID int64) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(blockedBucket).Delete(chatKey(chatID))
+	})
+	if err == nil {
+		b.cache(chatID, false)
+	}
+	return err
+}
+
+// cache updates the list in memory if it's already loaded
+func (b *boltBlockList) cache(chatID int64, blocked bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.blocked == nil {
+		return
+	}
+	if blocked {
+		b.blocked[chatID] = true
+	} else {
+		delete(b.blocked, chatID)
+	}
+}
+
+// IsBlocked reports whether chat is in the list. The database is read only by the
+// first call.
+func (b *boltBlockList) IsBlocked(chatID int64) (bool, error) {
+	b.mu.RLock()
+	blocked, loaded := b.blocked[chatID], b.blocked != nil
+	b.mu.RUnlock()
+	if loaded {
+		return blocked, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.blocked == nil {
+		chats, err := b.List()
+		if err != nil {
+			return false, err
+		}
+		b.blocked = make(map[int64]bool, len(chats))
+		for _, id := range chats {
+			b.blocked[id] = true
+		}
+	}
+	return b.blocked[chatID], nil
+}
+
+// List returns all blocked chats
+func (b *boltBlockList) List() ([]int64, error) {
+	chats := []int64{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(blockedBucket).ForEach(func(k, v []byte) error {
+			id, err := strconv.ParseInt(string(k), 10, 64)
+			if err != nil {
+				return errors.Wrapf(err, "bad blocked chat %q", k)
+			}
+			chats = append(chats, id)
+			return nil
+		})
+	})
+	return chats, err
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"text/template"
 
+	"github.com/go-telegram-bot-api/telegram-bot-api"
 	"github.com/pkg/errors"
 	"github.com/rpeshkov/slovnik"
 )
@@ -15,7 +16,8 @@ type Template struct {
 
 func CreateTemplate() (*Template, error) {
 	funcs := template.FuncMap{
-		"join": strings.Join,
+		"join":   strings.Join,
+		"escape": escapeMarkdown,
 	}
 
 	templates, err := template.New("").Funcs(funcs).ParseGlob("./templates/*.gotmpl")
@@ -33,8 +35,57 @@ func (t *Template) Translation(words []*slovnik.Word) string {
 	return buf.String()
 }
 
-func (t *Template) Phrases(words []*slovnik.Word) string {
+func (t *Template) Phrases(page phrasesPage) string {
 	var buf bytes.Buffer
-	t.tmpl.ExecuteTemplate(&buf, "phrases", words)
+	t.tmpl.ExecuteTemplate(&buf, "phrases", page)
 	return buf.String()
 }
+
+func (t *Template) Short(words []*slovnik.Word) string {
+	var buf bytes.Buffer
+	t.tmpl.ExecuteTemplate(&buf, "short", words)
+	return buf.String()
+}
+
+func (t *Template) Vocabulary(entries []*VocabularyEntry) string {
+	var buf bytes.Buffer
+	t.tmpl.ExecuteTemplate(&buf, "vocabulary", entries)
+	return buf.String()
+}
+
+func (t *Template) Start(user *tgbotapi.User) string {
+	var buf bytes.Buffer
+	t.tmpl.ExecuteTemplate(&buf, "start", user)
+	return buf.String()
+}
+
+func (t *Template) Help(commands []botCommand) string {
+	var buf bytes.Buffer
+	t.tmpl.ExecuteTemplate(&buf, "help", commands)
+	return buf.String()
+}
+
+func (t *Template) About() string {
+	var buf bytes.Buffer
+	t.tmpl.ExecuteTemplate(&buf, "about", nil)
+	return buf.String()
+}
+
+func (t *Template) Settings(s ChatSettings) string {
+	var buf bytes.Buffer
+	t.tmpl.ExecuteTemplate(&buf, "settings", s)
+	return buf.String()
+}
+
+func (t *Template) Glossary(g glossary) string {
+	var buf bytes.Buffer
+	t.tmpl.ExecuteTemplate(&buf, "glossary", g)
+	return buf.String()
+}
+
+// markdownReplacer escapes characters that have special meaning in telegram markdown
+var markdownReplacer = strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+
+func escapeMarkdown(s string) string {
+	return markdownReplacer.Replace(s)
+}
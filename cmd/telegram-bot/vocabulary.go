@@ -0,0 +1,124 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rpeshkov/slovnik"
+)
+
+// Initial ease factor of SM-2 algorithm
+const (
+	defaultEaseFactor = 2.5
+	minEaseFactor     = 1.3
+)
+
+// Answer quality according to SM-2 algorithm, 0 is the worst and 5 is the perfect answer
+const (
+	qualityForgotten = 0
+	qualityWrong     = 1
+	qualityAccents   = 4
+	qualityPerfect   = 5
+	qualityPassing   = 3
+)
+
+// VocabularyEntry is a word saved by the user together with its repetition schedule
+type VocabularyEntry struct {
+	Word  slovnik.Word `json:"word"`
+	Added time.Time    `json:"added"`
+
+	Repetitions int       `json:"repetitions"`
+	Interval    int       `json:"interval"`
+	EaseFactor  float64   `json:"easeFactor"`
+	Due         time.Time `json:"due"`
+}
+
+// newVocabularyEntry creates entry that is due for repetition immediately
+func newVocabularyEntry(w slovnik.Word, now time.Time) *VocabularyEntry {
+	return &VocabularyEntry{
+		Word:       w,
+		Added:      now,
+		EaseFactor: defaultEaseFactor,
+		Due:        now,
+	}
+}
+
+// Review updates repetition schedule of the entry using SM-2 algorithm
+func (e *VocabularyEntry) Review(quality int, now time.Time) {
+	if quality >= qualityPassing {
+		switch e.Repetitions {
+		case 0:
+			e.Interval = 1
+		case 1:
+			e.Interval = 6
+		default:
+			e.Interval = int(math.Round(float64(e.Interval) * e.EaseFactor))
+		}
+		e.Repetitions++
+	} else {
+		e.Repetitions = 0
+		e.Interval = 1
+	}
+
+	q := float64(5 - quality)
+	e.EaseFactor += 0.1 - q*(0.08+q*0.02)
+	if e.EaseFactor < minEaseFactor {
+		e.EaseFactor = minEaseFactor
+	}
+
+	e.Due = now.AddDate(0, 0, e.Interval)
+}
+
+// VocabularyStore keeps words saved by users
+type VocabularyStore interface {
+	// Save creates or replaces entry in the chat vocabulary
+	Save(chatID int64, e *VocabularyEntry) error
+
+	// Get returns entry for the word or nil if the word is not saved
+	Get(chatID int64, word string) (*VocabularyEntry, error)
+
+	// List returns all entries of the chat vocabulary
+	List(chatID int64) ([]*VocabularyEntry, error)
+}
+
+// nextDue returns entry that should be repeated first
+func nextDue(entries []*VocabularyEntry) *VocabularyEntry {
+	var next *VocabularyEntry
+	for _, e := range entries {
+		if next == nil || e.Due.Before(next.Due) {
+			next = e
+		}
+	}
+	return next
+}
+
+// gradeAnswer compares answer with translations of the word and returns answer quality
+func gradeAnswer(w slovnik.Word, answer string) int {
+	quality := qualityWrong
+	for _, t := range w.Translations {
+		if slovnik.Fold(t) != slovnik.Fold(answer) {
+			continue
+		}
+
+		if stripStress(t) == stripStress(answer) {
+			return qualityPerfect
+		}
+		quality = qualityAccents
+	}
+	return quality
+}
+
+// stripStress removes stress marks from the word and lowercases it. Stress marks are shown
+// in translations, but users never type them.
+func stripStress(s string) string {
+	const stressMark = '\u0301'
+
+	runes := []rune{}
+	for _, r := range []rune(s) {
+		if r != stressMark {
+			runes = append(runes, r)
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(string(runes)))
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltVocabulary is a VocabularyStore that keeps words in embedded bolt database.
+// Each chat has its own bucket with entries keyed by the word.
+type boltVocabulary struct {
+	db *bolt.DB
+}
+
+// newBoltVocabulary creates vocabulary store in the database opened by openDatabase
+func newBoltVocabulary(db *bolt.DB) *boltVocabulary {
+	return &boltVocabulary{db}
+}
+
+// Save creates or replaces entry in the chat vocabulary
+func (v *boltVocabulary) Save(chatID int64, e *VocabularyEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	return v.db.Update(func(tx *bolt.Tx) error {
+		chat, err := tx.Bucket(vocabularyBucket).CreateBucketIfNotExists(chatKey(chatID))
+		if err != nil {
+			return err
+		}
+		return chat.Put([]byte(e.Word.Word), data)
+	})
+}
+
+// Get returns entry for the word or nil if the word is not saved
+func (v *boltVocabulary) Get(chatID int64, word string) (*VocabularyEntry, error) {
+	var entry *VocabularyEntry
+
+	err := v.db.View(func(tx *bolt.Tx) error {
+		chat := tx.Bucket(vocabularyBucket).Bucket(chatKey(chatID))
+		if chat == nil {
+			return nil
+		}
+
+		data := chat.Get([]byte(word))
+		if data == nil {
+			return nil
+		}
+
+		entry = &VocabularyEntry{}
+		return json.Unmarshal(data, entry)
+	})
+
+	return entry, err
+}
+
+// List returns all entries of the chat vocabulary ordered by word
+func (v *boltVocabulary) List(chatID int64) ([]*VocabularyEntry, error) {
+	entries := []*VocabularyEntry{}
+
+	err := v.db.View(func(tx *bolt.Tx) error {
+		chat := tx.Bucket(vocabularyBucket).Bucket(chatKey(chatID))
+		if chat == nil {
+			return nil
+		}
+
+		return chat.ForEach(func(k, data []byte) error {
+			e := &VocabularyEntry{}
+			if err := json.Unmarshal(data, e); err != nil {
+				return errors.Wrapf(err, "bad vocabulary entry %q", k)
+			}
+			entries = append(entries, e)
+			return nil
+		})
+	})
+
+	return entries, err
+}
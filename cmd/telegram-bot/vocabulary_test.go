@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/rpeshkov/slovnik"
+)
+
+func TestReview(t *testing.T) {
+	now := time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC)
+	e := newVocabularyEntry(slovnik.Word{Word: "hlavní"}, now)
+
+	cases := []struct {
+		quality  int
+		interval int
+	}{
+		{qualityPerfect, 1},
+		{qualityPerfect, 6},
+		{qualityAccents, 16},
+		{qualityWrong, 1},
+		{qualityPerfect, 1},
+	}
+
+	for i, c := range cases {
+		e.Review(c.quality, now)
+		if e.Interval != c.interval {
+			t.Errorf("Review #%d interval == %d, want %d", i, e.Interval, c.interval)
+		}
+		if want := now.AddDate(0, 0, c.interval); !e.Due.Equal(want) {
+			t.Errorf("Review #%d due == %v, want %v", i, e.Due, want)
+		}
+		if e.EaseFactor < minEaseFactor {
+			t.Errorf("Review #%d ease factor == %v, want >= %v", i, e.EaseFactor, minEaseFactor)
+		}
+	}
+}
+
+func TestGradeAnswer(t *testing.T) {
+	w := slovnik.Word{Word: "главный", Translations: []string{"hlavní", "ústřední"}}
+
+	cases := []struct {
+		answer string
+		want   int
+	}{
+		{"hlavní", qualityPerfect},
+		{" Ústřední ", qualityPerfect},
+		{"hlavni", qualityAccents},
+		{"ustredni", qualityAccents},
+		{"vedlejší", qualityWrong},
+	}
+
+	for _, c := range cases {
+		got := gradeAnswer(w, c.answer)
+		if got != c.want {
+			t.Errorf("gradeAnswer(%q) == %d, want %d", c.answer, got, c.want)
+		}
+	}
+
+	stressed := slovnik.Word{Word: "hlavní", Translations: []string{"гла́вный"}}
+	if got := gradeAnswer(stressed, "главный"); got != qualityPerfect {
+		t.Errorf("gradeAnswer(%q) == %d, want %d", "главный", got, qualityPerfect)
+	}
+}
+
+func TestRevealAnswer(t *testing.T) {
+	bot, telegram := newTestBot(t, stubTranslator{})
+	const chatID = 1
+
+	for _, w := range []string{"hlavní", "město"} {
+		if err := bot.vocabulary.Save(chatID, newVocabularyEntry(slovnik.Word{Word: w}, time.Now())); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reveal := func(word string) {
+		bot.revealAnswer(&tgbotapi.CallbackQuery{
+			ID:      "1",
+			Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatID}},
+		}, bot.tokens.Token(word))
+	}
+
+	// The button of the previous question doesn't close the current one
+	bot.quizzes.Start(chatID, "město")
+	reveal("hlavní")
+	if !bot.checkQuizAnswer(chatID, "город") {
+		t.Fatalf("question is closed by the button of another word")
+	}
+	if calls := telegram.Calls("sendMessage"); len(calls) != 1 {
+		t.Fatalf("sendMessage is called %d times, want 1 for the answer", len(calls))
+	}
+
+	bot.quizzes.Start(chatID, "město")
+	reveal("město")
+	if calls := telegram.Calls("sendMessage"); len(calls) != 2 {
+		t.Errorf("sendMessage is called %d times, want the answer to be revealed", len(calls))
+	}
+	if _, ok := bot.quizzes.Finish(chatID); ok {
+		t.Errorf("question isn't closed after the answer is revealed")
+	}
+}
+
+// directionTranslator finds words only when they're translated from the language
+type directionTranslator struct {
+	language slovnik.Language
+	words    stubTranslator
+}
+
+func (d directionTranslator) Translate(ctx context.Context, word string, language slovnik.Language) ([]*slovnik.Word, error) {
+	if language != d.language {
+		return nil, nil
+	}
+	return d.words[word], nil
+}
+
+func TestSaveWord(t *testing.T) {
+	bot, telegram := newTestBot(t, directionTranslator{slovnik.Ru, stubTranslator{
+		"собака": {{Word: "собака", WordType: "ж", Translations: []string{"pes"}}},
+		"кошка":  {{Word: "кошечка", Translations: []string{"kočička"}}},
+	}})
+	const chatID = 1
+
+	save := func(word string) string {
+		bot.saveWord(context.Background(), &tgbotapi.CallbackQuery{
+			ID:      word,
+			Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatID}},
+		}, bot.tokens.Token(word))
+
+		for _, c := range telegram.Calls("answerCallbackQuery") {
+			if c.Get("callback_query_id") == word {
+				return c.Get("text")
+			}
+		}
+		return ""
+	}
+
+	cases := []struct {
+		word  string
+		saved bool
+	}{
+		{"собака", true},
+		// Single suggestion isn't the entry of the word
+		{"кошка", false},
+	}
+	for _, c := range cases {
+		answer := save(c.word)
+		e, err := bot.vocabulary.Get(chatID, c.word)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (e != nil) != c.saved {
+			t.Errorf("saveWord(%q) answered %q, saved == %v, want %v", c.word, answer, e != nil, c.saved)
+		}
+	}
+}
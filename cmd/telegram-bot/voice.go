@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/rpeshkov/slovnik"
+)
+
+const (
+	// Voice messages longer than this aren't recognized, a word takes a few seconds to say
+	maxVoiceDuration = 15
+
+	// Voice files larger than this aren't downloaded
+	maxVoiceSize = 1 << 20
+)
+
+// handleVoice recognizes the text of voice message and translates it
+func (bot *Bot) handleVoice(ctx context.Context, message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	if bot.recognizer == nil {
+		bot.respond(chatID, "Голосовые сообщения не поддерживаются, напишите слово текстом")
+		return
+	}
+
+	if message.Voice.Duration > maxVoiceDuration || message.Voice.FileSize > maxVoiceSize {
+		bot.respond(chatID, "Слишком длинное сообщение, произнесите одно слово или фразу")
+		return
+	}
+
+	audio, err := bot.downloadFile(message.Voice.FileID)
+	if err != nil {
+		bot.respondError(chatID, "Something bad happened :(")
+		bot.logger.Error("unable to download voice message", "chat_id", chatID, "err", err)
+		return
+	}
+	defer audio.Close()
+
+	text, err := recognizeText(ctx, bot.recognizer, audio)
+	if err != nil {
+		bot.respondError(chatID, "Не удалось распознать сообщение :(")
+		bot.logger.Error("speech recognition failed", "chat_id", chatID, "err", err)
+		return
+	}
+
+	if err = validateInput(text, bot.maxInputLength); err != nil {
+		bot.respondError(chatID, "Не удалось распознать слово, попробуйте ещё раз")
+		return
+	}
+
+	bot.translateText(ctx, chatID, text, "🎤 _"+escapeMarkdown(text)+"_\n\n")
+}
+
+// downloadFile returns contents of the file sent to the bot
+func (bot *Bot) downloadFile(fileID string) (io.ReadCloser, error) {
+	u, err := bot.api.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get file URL")
+	}
+
+	r, err := bot.api.Client.Get(u)
+	if err != nil {
+		return nil, errors.Wrap(err, "file download failed")
+	}
+
+	if r.StatusCode != http.StatusOK {
+		r.Body.Close()
+		return nil, errors.Errorf("file download failed with status %d", r.StatusCode)
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(r.Body, maxVoiceSize), r.Body}, nil
+}
+
+// recognizeText returns the text spoken in audio. Punctuation that speech engines add at
+// the ends of sentences is removed.
+func recognizeText(ctx context.Context, recognizer slovnik.SpeechRecognizer, audio io.Reader) (string, error) {
+	text, err := recognizer.Recognize(ctx, audio)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Trim(text, " .,!?…\"«»"), nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rpeshkov/slovnik/speech"
+)
+
+func TestRecognizeText(t *testing.T) {
+	cases := []struct {
+		recognized string
+		want       string
+	}{
+		{"hlavní", "hlavní"},
+		{" Hlavní město.", "Hlavní město"},
+		{"«Столица»!", "Столица"},
+		{"...", ""},
+	}
+
+	for _, c := range cases {
+		r := &speech.FakeRecognizer{Text: c.recognized}
+		got, err := recognizeText(context.Background(), r, strings.NewReader("OggS"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != c.want {
+			t.Errorf("recognizeText(%q) == %q, want %q", c.recognized, got, c.want)
+		}
+		if string(r.Audio) != "OggS" {
+			t.Errorf("recognizer got audio %q, want %q", r.Audio, "OggS")
+		}
+	}
+
+	r := &speech.FakeRecognizer{Err: errors.New("engine failed")}
+	if _, err := recognizeText(context.Background(), r, strings.NewReader("OggS")); err == nil {
+		t.Errorf("recognizeText() error == nil, want engine error")
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/rpeshkov/slovnik/metrics"
+)
+
+// secretTokenHeader contains the secret that was passed to telegram with setWebhook
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// Maximum size of update accepted by webhook handler
+const maxUpdateSize = 1 << 20
+
+// webhookHandler receives updates sent by telegram and passes them to the channel.
+// Requests without matching secret are rejected when secret isn't empty.
+func webhookHandler(secret string, updates chan<- tgbotapi.Update) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		token := r.Header.Get(secretTokenHeader)
+		if secret != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUpdateSize)).Decode(&update); err != nil {
+			http.Error(w, "bad update", http.StatusBadRequest)
+			return
+		}
+
+		updates <- update
+	}
+}
+
+// healthHandler reports that the process is alive
+func healthHandler(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok\n"))
+}
+
+// readyHandler reports whether the bot is processing updates. It's not ready before
+// listening starts and after shutdown begins.
+func readyHandler(ready *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(ready) == 0 {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	}
+}
+
+// newServeMux creates handlers for health checks and metrics. They're served in both
+// polling and webhook modes, webhook handler is added to the mux in the latter.
+func newServeMux(ready *int32) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", healthHandler)
+	mux.Handle("/readyz", readyHandler(ready))
+	mux.Handle("/metrics", metrics.Handler())
+	return mux
+}
+
+// setWebhook registers webhook URL in telegram. Certificate is uploaded when TLS is
+// served by the bot, so self-signed certificates can be used.
+func setWebhook(api *tgbotapi.BotAPI, config *Config) error {
+	var err error
+	if config.WebhookCert != "" {
+		params := map[string]string{"url": config.WebhookURL}
+		if config.WebhookSecret != "" {
+			params["secret_token"] = config.WebhookSecret
+		}
+		_, err = api.UploadFile("setWebhook", params, "certificate", config.WebhookCert)
+	} else {
+		params := url.Values{}
+		params.Add("url", config.WebhookURL)
+		if config.WebhookSecret != "" {
+			params.Add("secret_token", config.WebhookSecret)
+		}
+		_, err = api.MakeRequest("setWebhook", params)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "webhook set failed")
+	}
+	return nil
+}
+
+// serve runs HTTP server until it's shut down. TLS is used only for webhook.
+func (bot *Bot) serve() error {
+	bot.logger.Info("listening", "addr", bot.server.Addr, "webhook", bot.webhook)
+	if bot.webhook && bot.certFile != "" {
+		return bot.server.ListenAndServeTLS(bot.certFile, bot.keyFile)
+	}
+	return bot.server.ListenAndServe()
+}
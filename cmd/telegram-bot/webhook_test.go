@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	updates := make(chan tgbotapi.Update, 1)
+	var ready int32
+	mux := newServeMux(&ready)
+	mux.Handle("/webhook", webhookHandler("s3cret", updates))
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		secret string
+		body   string
+		status int
+	}{
+		{"update", http.MethodPost, "/webhook", "s3cret", `{"update_id": 7}`, http.StatusOK},
+		{"no secret", http.MethodPost, "/webhook", "", `{"update_id": 8}`, http.StatusUnauthorized},
+		{"wrong secret", http.MethodPost, "/webhook", "guess", `{"update_id": 9}`, http.StatusUnauthorized},
+		{"bad body", http.MethodPost, "/webhook", "s3cret", `{`, http.StatusBadRequest},
+		{"get", http.MethodGet, "/webhook", "s3cret", "", http.StatusMethodNotAllowed},
+		{"token path", http.MethodPost, "/bot123:token", "s3cret", `{"update_id": 10}`, http.StatusNotFound},
+		{"healthz", http.MethodGet, "/healthz", "", "", http.StatusOK},
+		{"readyz", http.MethodGet, "/readyz", "", "", http.StatusServiceUnavailable},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+		if c.secret != "" {
+			r.Header.Set(secretTokenHeader, c.secret)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+
+		if w.Code != c.status {
+			t.Errorf("%s %s (%s) status == %d, want %d", c.method, c.path, c.name, w.Code, c.status)
+		}
+	}
+
+	if len(updates) != 1 {
+		t.Fatalf("%d updates received, want 1", len(updates))
+	}
+	if u := <-updates; u.UpdateID != 7 {
+		t.Errorf("update ID == %d, want 7", u.UpdateID)
+	}
+
+	ready = 1
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("/readyz status == %d when ready, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestServeMuxWithoutWebhook(t *testing.T) {
+	ready := int32(1)
+	mux := newServeMux(&ready)
+
+	cases := []struct {
+		path   string
+		status int
+	}{
+		{"/healthz", http.StatusOK},
+		{"/readyz", http.StatusOK},
+		{"/metrics", http.StatusOK},
+		{"/webhook", http.StatusNotFound},
+	}
+
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, c.path, nil))
+		if w.Code != c.status {
+			t.Errorf("GET %s status == %d, want %d", c.path, w.Code, c.status)
+		}
+	}
+}
+
+func TestValidSecret(t *testing.T) {
+	cases := []struct {
+		secret string
+		want   bool
+	}{
+		{"", true},
+		{"abc_DEF-123", true},
+		{"with space", false},
+		{"секрет", false},
+		{strings.Repeat("a", 257), false},
+	}
+
+	for _, c := range cases {
+		if got := validSecret(c.secret); got != c.want {
+			t.Errorf("validSecret(%q) == %v, want %v", c.secret, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+// Package config loads configuration of slovnik commands. Options are described by tags
+// of configuration struct fields and are taken from defaults, optional YAML or TOML file,
+// environment variables and command line flags. Each source overrides the previous one.
+//
+// Supported tags:
+//
+//	key       name of the option in configuration file, flag and environment variable names are derived from it
+//	default   value used when the option isn't set
+//	desc      description of the option
+//	required  "true" if the option must be set
+//	secret    "true" if the value must not be printed
+//	example   value written to environment template
+//	min       minimal value of a number or duration
+//
+// Fields of nested structs without key tag are options as well, so common options can be
+// shared by several commands.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix is added to option keys to get names of environment variables
+const EnvPrefix = "SLOVNIK_"
+
+// EnvFile is a variable that contains path to configuration file
+const EnvFile = EnvPrefix + "CONFIG"
+
+// ErrExit is returned by Load when it printed information requested by flags, such as help
+// or configuration, and the program should exit
+var ErrExit = errors.New("config: exit requested")
+
+// Validator is implemented by configurations that have constraints not expressed by tags.
+// Validate is called after all sources are loaded.
+type Validator interface {
+	Validate() error
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// option is a configuration struct field described by tags
+type option struct {
+	Key      string
+	Default  string
+	Desc     string
+	Required bool
+	Secret   bool
+	Example  string
+	Min      string
+
+	value reflect.Value
+}
+
+// Env returns name of environment variable of the option
+func (o *option) Env() string {
+	return EnvPrefix + strings.ToUpper(o.Key)
+}
+
+// Flag returns name of command line flag of the option
+func (o *option) Flag() string {
+	return strings.Replace(o.Key, "_", "-", -1)
+}
+
+// options returns all options of configuration struct
+func options(cfg interface{}) ([]*option, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: %T is not a pointer to struct", cfg)
+	}
+	return structOptions(v.Elem())
+}
+
+func structOptions(v reflect.Value) ([]*option, error) {
+	opts := []*option{}
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, ok := f.Tag.Lookup("key")
+
+		if !ok {
+			if f.Type.Kind() == reflect.Struct && f.Type != durationType {
+				nested, err := structOptions(v.Field(i))
+				if err != nil {
+					return nil, err
+				}
+				opts = append(opts, nested...)
+			}
+			continue
+		}
+
+		o := &option{
+			Key:      key,
+			Default:  f.Tag.Get("default"),
+			Desc:     f.Tag.Get("desc"),
+			Required: f.Tag.Get("required") == "true",
+			Secret:   f.Tag.Get("secret") == "true",
+			Example:  f.Tag.Get("example"),
+			Min:      f.Tag.Get("min"),
+			value:    v.Field(i),
+		}
+		if !supported(f.Type) {
+			return nil, fmt.Errorf("config: option %s has unsupported type %s", key, f.Type)
+		}
+		opts = append(opts, o)
+	}
+
+	return opts, nil
+}
+
+func supported(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64:
+		return true
+	case reflect.Slice:
+		k := t.Elem().Kind()
+		return k == reflect.String || k == reflect.Int || k == reflect.Int64
+	}
+	return false
+}
+
+// set parses the value and assigns it to the option
+func (o *option) set(s string) error {
+	v := o.value
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration like 10s", s)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", s)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int || v.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", s)
+		}
+		v.SetInt(n)
+	case v.Kind() == reflect.Slice:
+		list := reflect.MakeSlice(v.Type(), 0, 0)
+		for _, item := range strings.Split(s, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			if v.Type().Elem().Kind() == reflect.String {
+				list = reflect.Append(list, reflect.ValueOf(item))
+				continue
+			}
+			n, err := strconv.ParseInt(item, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%q is not a comma separated list of numbers", s)
+			}
+			list = reflect.Append(list, reflect.ValueOf(n).Convert(v.Type().Elem()))
+		}
+		v.Set(list)
+	}
+	return nil
+}
+
+// String formats the value, so that it can be parsed by set
+func (o *option) String() string {
+	v := o.value
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// isZero reports whether the option has no value
+func (o *option) isZero() bool {
+	if o.value.Kind() == reflect.Slice {
+		return o.value.Len() == 0
+	}
+	return o.value.Interface() == reflect.Zero(o.value.Type()).Interface()
+}
+
+// check validates the value against min tag
+func (o *option) check() error {
+	if o.Min == "" {
+		return nil
+	}
+
+	if k := o.value.Kind(); k != reflect.Int && k != reflect.Int64 {
+		return fmt.Errorf("config: min is set for option %s that isn't a number", o.Key)
+	}
+
+	min := &option{value: reflect.New(o.value.Type()).Elem()}
+	if err := min.set(o.Min); err != nil {
+		return fmt.Errorf("config: bad min of option %s: %v", o.Key, err)
+	}
+	if o.value.Int() < min.value.Int() {
+		return fmt.Errorf("%s must be at least %s", o.Env(), min)
+	}
+	return nil
+}
+
+// Load fills cfg from all sources. Name is a program name shown in help, args are command
+// line arguments without program name.
+//
+// Besides the flags of the options, -config sets configuration file, -print-config prints
+// loaded configuration with secrets redacted and -env-template prints template of
+// environment file. ErrExit is returned when something is printed.
+func Load(name string, cfg interface{}, args []string) error {
+	opts, err := options(cfg)
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	file := fs.String("config", os.Getenv(EnvFile), "path to YAML or TOML configuration file, also set by "+EnvFile)
+	printConfig := fs.Bool("print-config", false, "print configuration with secrets redacted and exit")
+	envTemplate := fs.Bool("env-template", false, "print template of environment file and exit")
+
+	flags := make(map[string]*option)
+	for _, o := range opts {
+		usage := o.Desc + " (" + o.Env() + ")"
+		if o.value.Kind() == reflect.Bool {
+			// Bool flags may be set without value like standard ones, e.g. -debug
+			def, _ := strconv.ParseBool(o.Default)
+			fs.Bool(o.Flag(), def, usage)
+		} else {
+			fs.String(o.Flag(), o.Default, usage)
+		}
+		flags[o.Flag()] = o
+	}
+
+	if err = fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ErrExit
+		}
+		return err
+	}
+
+	if *envTemplate {
+		if err = WriteEnvTemplate(os.Stdout, cfg); err != nil {
+			return err
+		}
+		return ErrExit
+	}
+
+	for _, o := range opts {
+		if o.Default == "" {
+			continue
+		}
+		if err = o.set(o.Default); err != nil {
+			return fmt.Errorf("config: bad default of option %s: %v", o.Key, err)
+		}
+	}
+
+	if *file != "" {
+		if err = loadFile(*file, opts); err != nil {
+			return err
+		}
+	}
+
+	for _, o := range opts {
+		if s, ok := os.LookupEnv(o.Env()); ok {
+			if err = o.set(s); err != nil {
+				return fmt.Errorf("%s: %v", o.Env(), err)
+			}
+		}
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		o, ok := flags[f.Name]
+		if !ok || err != nil {
+			return
+		}
+		if e := o.set(f.Value.String()); e != nil {
+			err = fmt.Errorf("-%s: %v", f.Name, e)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = validate(cfg, opts); err != nil {
+		return err
+	}
+
+	if *printConfig {
+		if err = Print(os.Stdout, cfg); err != nil {
+			return err
+		}
+		return ErrExit
+	}
+
+	return nil
+}
+
+// validate checks required options, minimal values and calls Validator
+func validate(cfg interface{}, opts []*option) error {
+	for _, o := range opts {
+		if o.Required && o.isZero() {
+			return fmt.Errorf("%s is not set", o.Env())
+		}
+		if err := o.check(); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := cfg.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// Print writes configuration in the format of configuration file. Values of secrets
+// are redacted.
+func Print(w io.Writer, cfg interface{}) error {
+	opts, err := options(cfg)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range opts {
+		value := o.String()
+		if o.Secret && !o.isZero() {
+			value = "REDACTED"
+		}
+		if _, err = fmt.Fprintf(w, "%s: %s\n", o.Key, strconv.Quote(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteEnvTemplate writes environment file with all options. Required options are set to
+// example value or placeholder, other options are commented out with example value or
+// default, so a copy of the template doesn't enable anything by itself.
+func WriteEnvTemplate(w io.Writer, cfg interface{}) error {
+	opts, err := options(cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "# Generated from configuration schema, edit the schema instead of this file")
+	for _, o := range opts {
+		fmt.Fprintln(w)
+		if o.Desc != "" {
+			fmt.Fprintf(w, "# %s\n", o.Desc)
+		}
+
+		value := o.Default
+		if o.Example != "" {
+			value = o.Example
+		} else if o.Required {
+			value = "..."
+		}
+
+		if o.Required {
+			fmt.Fprintf(w, "%s=%s\n", o.Env(), value)
+		} else {
+			fmt.Fprintf(w, "# %s=%s\n", o.Env(), value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package config_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rpeshkov/slovnik/config"
+	"go.opentelemetry.io/otel"
+)
+
+type shared struct {
+	Timeout time.Duration `key:"test_timeout" default:"10s" min:"1ms"`
+}
+
+type testConfig struct {
+	shared
+
+	Token   string   `key:"test_token" required:"true" secret:"true"`
+	Listen  string   `key:"test_listen" default:":8080"`
+	Workers int      `key:"test_workers" default:"8" min:"1"`
+	Debug   bool     `key:"test_debug"`
+	Admins  []int    `key:"test_admins"`
+	Hosts   []string `key:"test_hosts"`
+	Webhook string   `key:"test_webhook" example:"https://bot.example.com"`
+
+	// Derived isn't an option because it has no key
+	Derived string
+}
+
+func (c *testConfig) Validate() error {
+	if c.Listen == "invalid" {
+		return errors.New("listen is invalid")
+	}
+	return nil
+}
+
+// setenv sets environment variables for the test and returns function that restores them
+func setenv(t *testing.T, env map[string]string) func() {
+	for k, v := range env {
+		if err := os.Setenv(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return func() {
+		for k := range env {
+			os.Unsetenv(k)
+		}
+	}
+}
+
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slovnik-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	yamlFile := writeFile(t, dir, "config.yaml", "test_token: from-file\ntest_listen: \":9000\"\ntest_workers: 2\ntest_admins: [1, 2]\n")
+	tomlFile := writeFile(t, dir, "config.toml", "test_token = \"from-file\"\ntest_listen = \":9000\"\ntest_workers = 2\ntest_admins = [1, 2]\n")
+
+	for _, file := range []string{yamlFile, tomlFile} {
+		c := &testConfig{}
+		if err = config.Load("test", c, []string{"-config", file}); err != nil {
+			t.Fatalf("Load(%s) error: %v", file, err)
+		}
+		if c.Token != "from-file" || c.Listen != ":9000" || c.Workers != 2 || !reflect.DeepEqual(c.Admins, []int{1, 2}) {
+			t.Errorf("Load(%s) == %+v, want values from file", file, c)
+		}
+		if c.Timeout != 10*time.Second {
+			t.Errorf("Load(%s) timeout == %v, want default 10s", file, c.Timeout)
+		}
+	}
+
+	defer setenv(t, map[string]string{
+		"SLOVNIK_TEST_LISTEN":  ":9100",
+		"SLOVNIK_TEST_WORKERS": "4",
+		"SLOVNIK_TEST_HOSTS":   "a.cz, b.cz",
+	})()
+
+	c := &testConfig{}
+	err = config.Load("test", c, []string{"-config", yamlFile, "-test-workers", "6", "-test-debug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &testConfig{
+		shared:  shared{10 * time.Second},
+		Token:   "from-file",
+		Listen:  ":9100",
+		Workers: 6,
+		Debug:   true,
+		Admins:  []int{1, 2},
+		Hosts:   []string{"a.cz", "b.cz"},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Load() == %+v, want %+v", c, want)
+	}
+}
+
+func TestLoadBoolFlags(t *testing.T) {
+	cases := []struct {
+		args  []string
+		debug bool
+	}{
+		{[]string{"-test-token", "x"}, false},
+		{[]string{"-test-token", "x", "-test-debug"}, true},
+		{[]string{"-test-debug", "-test-token", "x"}, true},
+		{[]string{"-test-token", "x", "-test-debug=false"}, false},
+	}
+
+	for _, c := range cases {
+		cfg := &testConfig{}
+		if err := config.Load("test", cfg, c.args); err != nil {
+			t.Errorf("Load(%q) error: %v", c.args, err)
+			continue
+		}
+		if cfg.Debug != c.debug {
+			t.Errorf("Load(%q) debug == %v, want %v", c.args, cfg.Debug, c.debug)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slovnik-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	unknown := writeFile(t, dir, "unknown.yaml", "test_token: x\ntest_lisen: \":80\"\n")
+	format := writeFile(t, dir, "config.json", "{}")
+
+	cases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"required", []string{}, "SLOVNIK_TEST_TOKEN is not set"},
+		{"min", []string{"-test-token", "x", "-test-workers", "0"}, "SLOVNIK_TEST_WORKERS must be at least 1"},
+		{"duration", []string{"-test-token", "x", "-test-timeout", "soon"}, "not a duration"},
+		{"number", []string{"-test-token", "x", "-test-workers", "many"}, "not a number"},
+		{"list", []string{"-test-token", "x", "-test-admins", "1,b"}, "list of numbers"},
+		{"validator", []string{"-test-token", "x", "-test-listen", "invalid"}, "listen is invalid"},
+		{"unknown key", []string{"-config", unknown}, "unknown option test_lisen"},
+		{"file format", []string{"-config", format}, "unknown format"},
+		{"missing file", []string{"-config", filepath.Join(dir, "missing.yaml")}, "missing.yaml"},
+	}
+
+	for _, c := range cases {
+		err := config.Load("test", &testConfig{}, c.args)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("Load(%s) error == %v, want %q", c.name, err, c.want)
+		}
+	}
+}
+
+func TestPrint(t *testing.T) {
+	c := &testConfig{Token: "secret-token", Listen: ":8080", Admins: []int{1, 2}}
+	c.Timeout = time.Minute
+
+	var buf bytes.Buffer
+	if err := config.Print(&buf, c); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "secret-token") {
+		t.Errorf("Print() shows secret:\n%s", out)
+	}
+	for _, line := range []string{`test_token: "REDACTED"`, `test_timeout: "1m0s"`, `test_admins: "1,2"`, `test_debug: "false"`} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Print() has no line %s:\n%s", line, out)
+		}
+	}
+}
+
+func TestWriteEnvTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	if err := config.WriteEnvTemplate(&buf, &testConfig{}); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	lines := []string{
+		"SLOVNIK_TEST_TOKEN=...",
+		"# SLOVNIK_TEST_LISTEN=:8080",
+		"# SLOVNIK_TEST_TIMEOUT=10s",
+		"# SLOVNIK_TEST_WEBHOOK=https://bot.example.com",
+		"# SLOVNIK_TEST_DEBUG=",
+	}
+	for _, line := range lines {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("WriteEnvTemplate() has no line %s:\n%s", line, out)
+		}
+	}
+}
+
+func TestLogger(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	var buf bytes.Buffer
+	l := config.Logging{LogLevel: "warn", LogFormat: "json"}
+	logger, err := l.Logger(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info("hidden")
+	logger.Warn("shown", "chat_id", 42)
+	if out := buf.String(); strings.Contains(out, "hidden") || !strings.Contains(out, `"chat_id":42`) {
+		t.Errorf("Logger() wrote %q, want only warning in JSON", out)
+	}
+
+	for _, bad := range []config.Logging{{LogLevel: "loud", LogFormat: "json"}, {LogLevel: "info", LogFormat: "xml"}} {
+		if _, err := bad.Logger(&buf); err == nil {
+			t.Errorf("Logger() with %+v succeeded, want error", bad)
+		}
+	}
+}
+
+func TestTracer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tracing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "traces.json")
+	tr := config.Tracing{TraceExporter: "stdout", TraceFile: file}
+	shutdown, err := tr.Tracer("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, span := otel.Tracer("test").Start(context.Background(), "test.span")
+	span.End()
+	if err = shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"test.span"`) {
+		t.Errorf("Tracer() exported %q, want span test.span", data)
+	}
+
+	bad := config.Tracing{TraceExporter: "jaeger"}
+	if _, err := bad.Tracer("test"); err == nil {
+		t.Errorf("Tracer() with %+v succeeded, want error", bad)
+	}
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// loadFile sets options from YAML or TOML file, format is chosen by file extension.
+// Keys of the file are option keys, unknown keys are reported as errors, so typos
+// don't go unnoticed.
+func loadFile(path string, opts []*option) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: %v", err)
+	}
+
+	values := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("config: unknown format of %s, use .yaml or .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("config: %s: %v", path, err)
+	}
+
+	keys := make(map[string]*option)
+	for _, o := range opts {
+		keys[o.Key] = o
+	}
+
+	for k, v := range values {
+		o, ok := keys[k]
+		if !ok {
+			return fmt.Errorf("config: %s: unknown option %s", path, k)
+		}
+		if err = o.set(fileValue(v)); err != nil {
+			return fmt.Errorf("config: %s: %s: %v", path, k, err)
+		}
+	}
+	return nil
+}
+
+// fileValue converts value decoded from file to the string form of the option.
+// Lists are joined with commas.
+func fileValue(v interface{}) string {
+	if list, ok := v.([]interface{}); ok {
+		items := make([]string, len(list))
+		for i, item := range list {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(v)
+}
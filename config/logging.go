@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Logging configures structured logger, it's shared by all commands
+type Logging struct {
+	LogLevel  string `key:"log_level" default:"info" desc:"Minimal level of logged messages: debug, info, warn or error"`
+	LogFormat string `key:"log_format" default:"json" desc:"Format of log messages: json or text"`
+}
+
+// Logger creates logger that writes to w and makes it default, so messages written with
+// log package are structured as well
+func (l *Logging) Logger(w io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(l.LogLevel)); err != nil {
+		return nil, fmt.Errorf("%sLOG_LEVEL must be debug, info, warn or error", EnvPrefix)
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(l.LogFormat) {
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("%sLOG_FORMAT must be json or text", EnvPrefix)
+	}
+
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+	return logger, nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Tracing configures export of OpenTelemetry traces, it's shared by all commands
+type Tracing struct {
+	TraceExporter string `key:"trace_exporter" default:"none" desc:"Exporter of traces: none, stdout or otlp"`
+	TraceFile     string `key:"trace_file" desc:"File that stdout exporter appends traces to, standard output is used when it's empty"`
+	OTLPEndpoint  string `key:"otlp_endpoint" default:"localhost:4318" desc:"Address of OTLP/HTTP collector"`
+	OTLPInsecure  bool   `key:"otlp_insecure" default:"true" desc:"Send traces to OTLP collector without TLS"`
+}
+
+// Tracer installs global tracer provider that exports traces of the service and W3C trace
+// context propagator. Returned function flushes pending spans and must be called on exit.
+func (t *Tracing) Tracer(service string) (func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var closer io.Closer
+	var err error
+
+	switch strings.ToLower(t.TraceExporter) {
+	case "none", "":
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		var w io.Writer = os.Stdout
+		if t.TraceFile != "" {
+			f, err := os.OpenFile(t.TraceFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				return nil, fmt.Errorf("unable to open trace file: %v", err)
+			}
+			w, closer = f, f
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(w))
+	case "otlp":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(t.OTLPEndpoint)}
+		if t.OTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(context.Background(), opts...)
+	default:
+		return nil, fmt.Errorf("%sTRACE_EXPORTER must be none, stdout or otlp", EnvPrefix)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to create trace exporter: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", service))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			closer.Close()
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+// Upstream configures access to the dictionary, it's shared by commands that translate words
+type Upstream struct {
+	SeznamURL       string        `key:"seznam_url" default:"https://slovnik.seznam.cz" desc:"Address of slovnik.seznam.cz or its mirror"`
+	UpstreamTimeout time.Duration `key:"upstream_timeout" default:"10s" min:"1ms" desc:"Timeout of requests to the dictionary"`
+}
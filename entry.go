@@ -0,0 +1,17 @@
+package slovnik
+
+// IsEntry reports whether words found for the query are its dictionary entry rather than
+// suggestions of similar words. Portal may suggest a single word, and suggestions carry
+// a translation as well, so the entry must have other details, be the query itself or be
+// found by the lemma of the query.
+func IsEntry(words []*Word, query string) bool {
+	if len(words) != 1 {
+		return false
+	}
+
+	w := words[0]
+	if w.WordType != "" || len(w.Samples) > 0 || len(w.Synonyms) > 0 || len(w.Antonyms) > 0 || len(w.DerivedWords) > 0 {
+		return true
+	}
+	return len(w.Translations) > 0 && (w.Lemma != "" || Fold(w.Word) == Fold(query))
+}
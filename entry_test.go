@@ -0,0 +1,27 @@
+package slovnik
+
+import "testing"
+
+func TestIsEntry(t *testing.T) {
+	cases := []struct {
+		name  string
+		words []*Word
+		query string
+		entry bool
+	}{
+		{"full entry", []*Word{{Word: "hlavní", WordType: "přídavné jméno", Translations: []string{"главный"}}}, "hlavní", true},
+		{"entry of other form", []*Word{{Word: "koza", WordType: "rod ženský", Translations: []string{"коза"}}}, "kozy", true},
+		{"translations only", []*Word{{Word: "protože", Translations: []string{"потому что"}}}, "Protoze", true},
+		{"found by lemma", []*Word{{Word: "dobrý", Translations: []string{"хороший"}, Lemma: "dobrý"}}, "dobrého", true},
+		{"single suggestion", []*Word{{Word: "dobrat", Translations: []string{"добрать"}}}, "dobrta", false},
+		{"suggestions", []*Word{{Word: "dobrý", WordType: "přídavné jméno"}, {Word: "dobro"}}, "dobr", false},
+		{"empty word", []*Word{{Word: "xyzzy"}}, "xyzzy", false},
+		{"nothing", []*Word{}, "xyzzy", false},
+	}
+
+	for _, c := range cases {
+		if got := IsEntry(c.words, c.query); got != c.entry {
+			t.Errorf("IsEntry(%s, %q) == %v, want %v", c.name, c.query, got, c.entry)
+		}
+	}
+}
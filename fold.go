@@ -0,0 +1,25 @@
+package slovnik
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Czech letters with diacritics and their base letters. Russian "ё" is usually written as "е".
+var foldReplacer = strings.NewReplacer(
+	"á", "a", "č", "c", "ď", "d", "é", "e", "ě", "e", "í", "i", "ň", "n", "ó", "o",
+	"ř", "r", "š", "s", "ť", "t", "ú", "u", "ů", "u", "ý", "y", "ž", "z", "ё", "е",
+)
+
+// Fold converts input to the form suitable for accent insensitive comparison. Letters are
+// lowercased, diacritics and stress marks are removed and spaces are collapsed.
+func Fold(input string) string {
+	s := strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, input)
+
+	return strings.Join(strings.Fields(foldReplacer.Replace(s)), " ")
+}
@@ -0,0 +1,27 @@
+package slovnik_test
+
+import (
+	"testing"
+
+	"github.com/rpeshkov/slovnik"
+)
+
+func TestFold(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"Hlavní", "hlavni"},
+		{"glávnyj", "glavnyj"},
+		{"гла́вный", "главный"},
+		{"Ёлка", "елка"},
+		{"  žluťoučký   kůň ", "zlutoucky kun"},
+	}
+
+	for _, c := range cases {
+		got := slovnik.Fold(c.in)
+		if got != c.want {
+			t.Errorf("Fold(%q) == %q, want %q", c.in, got, c.want)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package slovnik
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 // Language of the input string
 type Language int
@@ -24,3 +27,22 @@ func DetectLanguage(input string) Language {
 	}
 	return Cz
 }
+
+// String returns the code of the language used in API requests
+func (l Language) String() string {
+	if l == Ru {
+		return "ru"
+	}
+	return "cz"
+}
+
+// ParseLanguage returns the language with provided code
+func ParseLanguage(code string) (Language, error) {
+	switch strings.ToLower(code) {
+	case "ru":
+		return Ru, nil
+	case "cz", "cs":
+		return Cz, nil
+	}
+	return Cz, fmt.Errorf("unknown language %q", code)
+}
@@ -23,3 +23,30 @@ func TestDetectLanguage(t *testing.T) {
 		}
 	}
 }
+
+func TestParseLanguage(t *testing.T) {
+	cases := []struct {
+		in   string
+		lang slovnik.Language
+		ok   bool
+	}{
+		{"cz", slovnik.Cz, true},
+		{"cs", slovnik.Cz, true},
+		{"RU", slovnik.Ru, true},
+		{"de", slovnik.Cz, false},
+		{"", slovnik.Cz, false},
+	}
+
+	for _, c := range cases {
+		got, err := slovnik.ParseLanguage(c.in)
+		if (err == nil) != c.ok || got != c.lang {
+			t.Errorf("ParseLanguage(%q) == %q, %v, want %q", c.in, got, err, c.lang)
+		}
+	}
+
+	for _, l := range []slovnik.Language{slovnik.Cz, slovnik.Ru} {
+		if got, _ := slovnik.ParseLanguage(l.String()); got != l {
+			t.Errorf("ParseLanguage(%q) == %q, want %q", l.String(), got, l)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Package lemma finds base forms of inflected Czech and Russian words, so they can be
+// looked up in the dictionary that has entries only for base forms.
+package lemma
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rpeshkov/slovnik"
+)
+
+// Stem that is left after suffix is removed must be at least this long
+const minStemLen = 2
+
+// MaxCandidates limits the number of lemmas generated for one word
+const MaxCandidates = 5
+
+func init() {
+	for _, r := range rules {
+		sort.SliceStable(r, func(i, j int) bool {
+			return utf8.RuneCountInString(r[i].suffix) > utf8.RuneCountInString(r[j].suffix)
+		})
+	}
+}
+
+// Candidates returns possible base forms of the word, the most probable ones go first.
+// The word itself is never returned. Phrases don't have candidates.
+func Candidates(word string, language slovnik.Language) []string {
+	word = strings.ToLower(strings.TrimSpace(word))
+	if word == "" || strings.ContainsAny(word, " \t") {
+		return nil
+	}
+
+	seen := map[string]bool{word: true}
+	candidates := []string{}
+
+	for _, r := range rules[language] {
+		if !strings.HasSuffix(word, r.suffix) {
+			continue
+		}
+
+		stem := strings.TrimSuffix(word, r.suffix)
+		if utf8.RuneCountInString(stem) < minStemLen {
+			continue
+		}
+
+		for _, replacement := range r.replacements {
+			c := stem + replacement
+			if seen[c] {
+				continue
+			}
+			seen[c] = true
+			candidates = append(candidates, c)
+
+			if len(candidates) == MaxCandidates {
+				return candidates
+			}
+		}
+	}
+
+	return candidates
+}
@@ -0,0 +1,102 @@
+package lemma_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rpeshkov/slovnik"
+	"github.com/rpeshkov/slovnik/lemma"
+)
+
+func TestCandidates(t *testing.T) {
+	cases := []struct {
+		in   string
+		lang slovnik.Language
+		want string
+	}{
+		{"hlavního", slovnik.Cz, "hlavní"},
+		{"města", slovnik.Cz, "město"},
+		{"Praze", slovnik.Cz, "praha"},
+		{"dobrého", slovnik.Cz, "dobrý"},
+		{"děláme", slovnik.Cz, "dělat"},
+		{"столицу", slovnik.Ru, "столица"},
+		{"главного", slovnik.Ru, "главный"},
+		{"городами", slovnik.Ru, "город"},
+		{"читаешь", slovnik.Ru, "читать"},
+	}
+
+	for _, c := range cases {
+		got := lemma.Candidates(c.in, c.lang)
+		if len(got) > lemma.MaxCandidates {
+			t.Errorf("len(Candidates(%q)) == %d, want <= %d", c.in, len(got), lemma.MaxCandidates)
+		}
+
+		found := false
+		for _, g := range got {
+			if g == c.want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Candidates(%q) == %q, want to contain %q", c.in, got, c.want)
+		}
+	}
+
+	if got := lemma.Candidates("hlavní město", slovnik.Cz); len(got) != 0 {
+		t.Errorf("Candidates(phrase) == %q, want none", got)
+	}
+}
+
+// dictionary is a translator that knows only listed words. Words listed with empty
+// headword get a single suggestion of other word.
+type dictionary map[string]string
+
+func (d dictionary) Translate(ctx context.Context, word string, language slovnik.Language) ([]*slovnik.Word, error) {
+	headword, ok := d[word]
+	if !ok {
+		return []*slovnik.Word{{Word: "a"}, {Word: "b"}}, nil
+	}
+	if headword == "" {
+		return []*slovnik.Word{{Word: word + "x", Translations: []string{"suggestion"}}}, nil
+	}
+	return []*slovnik.Word{{Word: headword, Translations: []string{"translation"}}}, nil
+}
+
+func TestTranslate(t *testing.T) {
+	translator := lemma.NewTranslator(dictionary{"hlavní": "hlavní", "столица": "столица", "dobrý": ""})
+
+	cases := []struct {
+		in    string
+		lang  slovnik.Language
+		word  string
+		lemma string
+	}{
+		{"hlavní", slovnik.Cz, "hlavní", ""},
+		{"hlavního", slovnik.Cz, "hlavní", "hlavní"},
+		{"столицей", slovnik.Ru, "столица", "столица"},
+	}
+
+	for _, c := range cases {
+		words, err := translator.Translate(context.Background(), c.in, c.lang)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(words) != 1 || words[0].Word != c.word || words[0].Lemma != c.lemma {
+			t.Errorf("Translate(%q) == %+v, want word %q with lemma %q", c.in, words, c.word, c.lemma)
+			continue
+		}
+		if form := words[0].Form; (c.lemma != "" && form != c.in) || (c.lemma == "" && form != "") {
+			t.Errorf("Translate(%q) form == %q, want requested form for words found by lemma", c.in, form)
+		}
+	}
+
+	words, _ := translator.Translate(context.Background(), "dobrého", slovnik.Cz)
+	if len(words) != 2 || words[0].Lemma != "" {
+		t.Errorf("Translate(%q) == %+v, want suggestions without lemma", "dobrého", words)
+	}
+
+	words, _ = translator.Translate(context.Background(), "xyzzy", slovnik.Cz)
+	if len(words) != 2 {
+		t.Errorf("Translate(%q) returned %d words, want suggestions for original word", "xyzzy", len(words))
+	}
+}
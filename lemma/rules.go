@@ -0,0 +1,120 @@
+package lemma
+
+import "github.com/rpeshkov/slovnik"
+
+// rule replaces inflectional suffix with endings of possible base forms
+type rule struct {
+	suffix       string
+	replacements []string
+}
+
+// Czech rules cover the most common endings of adjectives, nouns and verbs in present tense
+var czechRules = []rule{
+	// adjectives
+	{"ího", []string{"í"}},
+	{"ímu", []string{"í"}},
+	{"ích", []string{"í", "e"}},
+	{"ími", []string{"í"}},
+	{"ého", []string{"ý"}},
+	{"ému", []string{"ý"}},
+	{"ých", []string{"ý"}},
+	{"ými", []string{"ý"}},
+	{"ém", []string{"ý"}},
+	{"ým", []string{"ý"}},
+	{"ou", []string{"ý", "a"}},
+	{"é", []string{"ý"}},
+	{"á", []string{"ý"}},
+
+	// nouns
+	{"ách", []string{"a"}},
+	{"ami", []string{"a", ""}},
+	{"ech", []string{"o", ""}},
+	{"ům", []string{""}},
+	{"ů", []string{""}},
+	{"ce", []string{"ka"}},
+	{"ze", []string{"ha"}},
+	{"ře", []string{"ra"}},
+	{"ště", []string{"sto"}},
+	{"y", []string{"a", ""}},
+	{"ě", []string{"a", "o"}},
+	{"u", []string{"a", "o", ""}},
+	{"a", []string{"o", ""}},
+	{"e", []string{"a"}},
+	{"i", []string{"e", ""}},
+	{"em", []string{"", "o"}},
+
+	// verbs
+	{"íme", []string{"it", "et"}},
+	{"íte", []string{"it", "et"}},
+	{"ím", []string{"it", "et"}},
+	{"íš", []string{"it", "et"}},
+	{"áme", []string{"at"}},
+	{"áte", []string{"at"}},
+	{"ám", []string{"at"}},
+	{"áš", []string{"at"}},
+	{"eme", []string{"at", "ovat"}},
+	{"ete", []string{"at", "ovat"}},
+	{"eš", []string{"at", "ovat"}},
+	{"uje", []string{"ovat"}},
+	{"uji", []string{"ovat"}},
+	{"ují", []string{"ovat"}},
+}
+
+// Russian rules cover the most common endings of adjectives, nouns and verbs in present tense
+var russianRules = []rule{
+	// adjectives
+	{"ого", []string{"ый", "ой", "ий"}},
+	{"его", []string{"ий"}},
+	{"ому", []string{"ый", "ой", "ий"}},
+	{"ему", []string{"ий"}},
+	{"ыми", []string{"ый"}},
+	{"ими", []string{"ий"}},
+	{"ых", []string{"ый"}},
+	{"их", []string{"ий"}},
+	{"ым", []string{"ый"}},
+	{"им", []string{"ий"}},
+	{"ая", []string{"ый", "ой"}},
+	{"яя", []string{"ий"}},
+	{"ую", []string{"ый", "ой"}},
+	{"юю", []string{"ий"}},
+	{"ое", []string{"ый", "ой"}},
+	{"ее", []string{"ий"}},
+	{"ые", []string{"ый"}},
+	{"ие", []string{"ий"}},
+
+	// nouns
+	{"ами", []string{"а", "", "о"}},
+	{"ями", []string{"я", "ь", "е"}},
+	{"ах", []string{"а", "", "о"}},
+	{"ях", []string{"я", "ь", "е"}},
+	{"ам", []string{"а", "", "о"}},
+	{"ям", []string{"я", "ь", "е"}},
+	{"ой", []string{"а"}},
+	{"ей", []string{"я", "ь", "а"}},
+	{"ом", []string{"", "о"}},
+	{"ем", []string{"ь", "е"}},
+	{"ов", []string{""}},
+	{"у", []string{"а", ""}},
+	{"ю", []string{"я", "ь"}},
+	{"ы", []string{"а", ""}},
+	{"и", []string{"я", "ь", "а"}},
+	{"е", []string{"а", "", "о"}},
+	{"а", []string{"", "о"}},
+	{"я", []string{"ь", "е"}},
+
+	// verbs
+	{"ешь", []string{"ть", "ать", "еть"}},
+	{"ет", []string{"ть", "ать", "еть"}},
+	{"ете", []string{"ть", "ать", "еть"}},
+	{"ют", []string{"ть", "ать", "ять"}},
+	{"ишь", []string{"ить"}},
+	{"ит", []string{"ить"}},
+	{"ите", []string{"ить"}},
+	{"ят", []string{"ить"}},
+	{"ат", []string{"ать"}},
+}
+
+var rules = map[slovnik.Language][]rule{
+	slovnik.Cz: czechRules,
+	slovnik.Ru: russianRules,
+}
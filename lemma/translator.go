@@ -0,0 +1,59 @@
+package lemma
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/rpeshkov/slovnik"
+)
+
+// Translator looks up base forms of the word when translator it wraps has no entry
+// for the word itself
+type Translator struct {
+	translator slovnik.Translator
+	logger     *slog.Logger
+}
+
+// NewTranslator creates lemmatizing translator on top of provided one
+func NewTranslator(translator slovnik.Translator) *Translator {
+	return &Translator{translator, slog.Default()}
+}
+
+// SetLogger sets logger of lemma lookups
+func (t *Translator) SetLogger(logger *slog.Logger) {
+	t.logger = logger
+}
+
+// Translate translates the word. If there's no entry for the word, candidate lemmas are tried
+// one by one and the first found entry is returned with Lemma field set to matched lemma
+// and Form set to the requested word.
+// When no lemma matches, result for the original word is returned.
+func (t *Translator) Translate(ctx context.Context, word string, language slovnik.Language) ([]*slovnik.Word, error) {
+	words, err := t.translator.Translate(ctx, word, language)
+	if err != nil || slovnik.IsEntry(words, word) {
+		return words, err
+	}
+
+	for _, c := range Candidates(word, language) {
+		found, err := t.translator.Translate(ctx, c, language)
+		if err != nil {
+			return nil, err
+		}
+
+		if isEntryOf(found, c) {
+			t.logger.Debug("lemma found", "word", word, "lemma", c, "direction", language.String())
+			found[0].Lemma = c
+			found[0].Form = word
+			return found, nil
+		}
+	}
+
+	t.logger.Debug("lemma not found", "word", word, "direction", language.String())
+	return words, nil
+}
+
+// isEntryOf reports whether result is the entry of the lemma itself, not a list of
+// suggestions or the entry of other word
+func isEntryOf(words []*slovnik.Word, lemma string) bool {
+	return slovnik.IsEntry(words, lemma) && slovnik.Fold(words[0].Word) == slovnik.Fold(lemma)
+}
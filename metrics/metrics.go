@@ -0,0 +1,168 @@
+// Package metrics collects Prometheus metrics of translation traffic. Translators and
+// seznam clients are wrapped to count lookups and measure latency, bots report updates
+// and cache lookups directly.
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rpeshkov/slovnik"
+	"github.com/rpeshkov/slovnik/seznam"
+)
+
+const namespace = "slovnik"
+
+// Kinds of lookup results
+const (
+	ResultFull    = "full"
+	ResultMistype = "mistype"
+	ResultEmpty   = "empty"
+	ResultError   = "error"
+)
+
+var (
+	lookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "lookups_total",
+		Help:      "Number of translation lookups by direction and kind of result.",
+	}, []string{"direction", "result"})
+
+	lookupDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "lookup_duration_seconds",
+		Help:      "Duration of translation lookups.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"direction"})
+
+	upstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_request_duration_seconds",
+		Help:      "Duration of requests to the dictionary until response headers are received.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"direction", "outcome"})
+
+	parseDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_parse_duration_seconds",
+		Help:      "Duration of reading and parsing of dictionary pages.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	})
+
+	cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_lookups_total",
+		Help:      "Number of cache lookups by cache and result, hit ratio is hits divided by all lookups.",
+	}, []string{"cache", "result"})
+
+	botUpdates = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bot_updates_total",
+		Help:      "Number of updates received by the bot by type.",
+	}, []string{"type"})
+)
+
+func init() {
+	prometheus.MustRegister(lookups, lookupDuration, upstreamDuration, parseDuration, cacheLookups, botUpdates)
+}
+
+// Handler serves collected metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// CacheLookup records hit or miss of the named cache
+func CacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheLookups.WithLabelValues(cache, result).Inc()
+}
+
+// BotUpdate records update of provided type received by the bot
+func BotUpdate(kind string) {
+	botUpdates.WithLabelValues(kind).Inc()
+}
+
+// resultKind returns kind of lookup result. Full page contains the entry of the word,
+// mistype page contains suggestions of words that could be meant.
+func resultKind(words []*slovnik.Word, word string, err error) string {
+	switch {
+	case err != nil:
+		return ResultError
+	case len(words) == 0:
+		return ResultEmpty
+	case slovnik.IsEntry(words, word):
+		return ResultFull
+	}
+	return ResultMistype
+}
+
+// Translator counts lookups of the wrapped translator
+type Translator struct {
+	translator slovnik.Translator
+}
+
+// NewTranslator wraps translator to collect metrics of its lookups
+func NewTranslator(translator slovnik.Translator) *Translator {
+	return &Translator{translator: translator}
+}
+
+// Translate translates the word by the wrapped translator and records the result
+func (t *Translator) Translate(ctx context.Context, word string, language slovnik.Language) ([]*slovnik.Word, error) {
+	start := time.Now()
+	words, err := t.translator.Translate(ctx, word, language)
+
+	direction := language.String()
+	lookupDuration.WithLabelValues(direction).Observe(time.Since(start).Seconds())
+	lookups.WithLabelValues(direction, resultKind(words, word, err)).Inc()
+	return words, err
+}
+
+// Client measures requests of the wrapped seznam client
+type Client struct {
+	client seznam.Getter
+}
+
+// NewClient wraps seznam client to collect metrics of upstream requests. Time between
+// the response and closing of its body is recorded as parse duration.
+func NewClient(client seznam.Getter) *Client {
+	return &Client{client: client}
+}
+
+// Get requests the page by the wrapped client
+func (c *Client) Get(ctx context.Context, word string, language slovnik.Language) (io.ReadCloser, error) {
+	start := time.Now()
+	body, err := c.client.Get(ctx, word, language)
+
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	upstreamDuration.WithLabelValues(language.String(), outcome).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		return nil, err
+	}
+	return &timedBody{ReadCloser: body, start: time.Now()}, nil
+}
+
+// timedBody records parse duration when it's closed
+type timedBody struct {
+	io.ReadCloser
+	start time.Time
+	once  sync.Once
+}
+
+func (b *timedBody) Close() error {
+	b.once.Do(func() {
+		parseDuration.Observe(time.Since(b.start).Seconds())
+	})
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/rpeshkov/slovnik"
+)
+
+type stubTranslator struct {
+	words []*slovnik.Word
+	err   error
+}
+
+func (t stubTranslator) Translate(ctx context.Context, word string, language slovnik.Language) ([]*slovnik.Word, error) {
+	return t.words, t.err
+}
+
+type stubGetter struct {
+	err error
+}
+
+func (g stubGetter) Get(ctx context.Context, word string, language slovnik.Language) (io.ReadCloser, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	return io.NopCloser(strings.NewReader("page")), nil
+}
+
+func TestTranslatorResults(t *testing.T) {
+	word := &slovnik.Word{Word: "dobrý", WordType: "přídavné jméno", Translations: []string{"хороший"}}
+	suggestion := &slovnik.Word{Word: "dobro", Translations: []string{"добро"}}
+	testData := []struct {
+		translator stubTranslator
+		language   slovnik.Language
+		expected   string
+	}{
+		{stubTranslator{words: []*slovnik.Word{word}}, slovnik.Cz, ResultFull},
+		{stubTranslator{words: []*slovnik.Word{word, word}}, slovnik.Cz, ResultMistype},
+		{stubTranslator{words: []*slovnik.Word{suggestion}}, slovnik.Cz, ResultMistype},
+		{stubTranslator{words: []*slovnik.Word{}}, slovnik.Ru, ResultEmpty},
+		{stubTranslator{err: errors.New("failed")}, slovnik.Ru, ResultError},
+	}
+
+	for _, d := range testData {
+		counter := lookups.WithLabelValues(d.language.String(), d.expected)
+		before := testutil.ToFloat64(counter)
+
+		NewTranslator(d.translator).Translate(context.Background(), "slovo", d.language)
+
+		if got := testutil.ToFloat64(counter) - before; got != 1 {
+			t.Errorf("lookups{%s,%s} increased by %v, want 1", d.language, d.expected, got)
+		}
+	}
+}
+
+func parseCount() uint64 {
+	m := &dto.Metric{}
+	parseDuration.Write(m)
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestClientParseDuration(t *testing.T) {
+	before := parseCount()
+
+	body, err := NewClient(stubGetter{}).Get(context.Background(), "slovo", slovnik.Cz)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	body.Close()
+	body.Close()
+
+	if got := parseCount() - before; got != 1 {
+		t.Errorf("parse duration observed %d times, want 1", got)
+	}
+
+	if _, err = NewClient(stubGetter{err: errors.New("failed")}).Get(context.Background(), "slovo", slovnik.Cz); err == nil {
+		t.Error("Get() of failing client succeeded")
+	}
+}
+
+func TestCacheLookup(t *testing.T) {
+	hits := cacheLookups.WithLabelValues("test", "hit")
+	misses := cacheLookups.WithLabelValues("test", "miss")
+
+	CacheLookup("test", true)
+	CacheLookup("test", false)
+	CacheLookup("test", false)
+
+	if got := testutil.ToFloat64(hits); got != 1 {
+		t.Errorf("cache hits == %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(misses); got != 2 {
+		t.Errorf("cache misses == %v, want 2", got)
+	}
+}
@@ -0,0 +1,196 @@
+// Package phrases finds sample phrases of dictionary entries by words they contain
+package phrases
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rpeshkov/slovnik"
+)
+
+// Phrase is a sample phrase of dictionary entry with its translation
+type Phrase struct {
+	// Headword is the word of the entry that has the phrase
+	Headword string
+
+	// Language is a language of the headword and the phrase, translation is in other one
+	Language slovnik.Language
+
+	Phrase      string
+	Translation string
+}
+
+// Match is a phrase found by the query. Phrases that match better have higher score.
+type Match struct {
+	Phrase
+	Score float64
+}
+
+// document is a folded text of the phrase, it's prepared once when the phrase is added
+type document struct {
+	// phrase and translation are folded words joined by joined
+	phrase      string
+	translation string
+
+	// length is a number of distinct words
+	length int
+}
+
+// Index is an inverted index of words of phrases and their translations. Words are folded,
+// so they match regardless of case, accents and stress marks. Index is safe for concurrent use.
+//
+// Index is kept in memory and phrases are never removed, so the number of phrases is
+// limited. Phrases added after the limit is reached aren't indexed.
+type Index struct {
+	mu       sync.RWMutex
+	limit    int
+	phrases  []Phrase
+	docs     []document
+	seen     map[string]bool
+	postings map[string][]int
+}
+
+// NewIndex creates empty index that keeps up to limit phrases, zero means no limit
+func NewIndex(limit int) *Index {
+	return &Index{
+		limit:    limit,
+		seen:     make(map[string]bool),
+		postings: make(map[string][]int),
+	}
+}
+
+// terms returns folded words of the text, each one is returned once
+func terms(text string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, t := range slovnik.Tokenize(text) {
+		t = slovnik.Fold(t)
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// joined returns folded words of the text separated and surrounded by spaces, so
+// sequences of words can be found in it
+func joined(text string) string {
+	return " " + strings.Join(terms(text), " ") + " "
+}
+
+// Len returns number of phrases in the index
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.phrases)
+}
+
+// Add indexes sample phrases of the headword. Phrases that are already indexed are skipped,
+// so entries may be added every time they're looked up. Nothing is added when the index
+// is full.
+func (x *Index) Add(headword string, language slovnik.Language, samples []slovnik.SampleUse) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for _, s := range samples {
+		if x.limit > 0 && len(x.phrases) >= x.limit {
+			return
+		}
+
+		key := language.String() + "\x00" + s.Phrase + "\x00" + s.Translation
+		if s.Phrase == "" || x.seen[key] {
+			continue
+		}
+		x.seen[key] = true
+
+		id := len(x.phrases)
+		x.phrases = append(x.phrases, Phrase{
+			Headword:    headword,
+			Language:    language,
+			Phrase:      s.Phrase,
+			Translation: s.Translation,
+		})
+
+		words := terms(s.Phrase + " " + s.Translation)
+		x.docs = append(x.docs, document{
+			phrase:      joined(s.Phrase),
+			translation: joined(s.Translation),
+			length:      len(words),
+		})
+		for _, t := range words {
+			x.postings[t] = append(x.postings[t], id)
+		}
+	}
+}
+
+// Search returns up to limit phrases that contain all words of the query either in the
+// phrase or in its translation. Rare words weigh more than frequent ones, short phrases
+// rank above long ones and phrases that contain the query as is rank above the rest.
+func (x *Index) Search(query string, limit int) []Match {
+	words := terms(query)
+	if len(words) == 0 {
+		return nil
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	// Rarest word goes first, so candidates are checked against shortest lists
+	sort.Slice(words, func(i, j int) bool { return len(x.postings[words[i]]) < len(x.postings[words[j]]) })
+
+	candidates := x.postings[words[0]]
+	for _, w := range words[1:] {
+		candidates = intersect(candidates, x.postings[w])
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	weight := 0.0
+	for _, w := range words {
+		weight += math.Log(1 + float64(len(x.phrases))/float64(len(x.postings[w])))
+	}
+
+	exact := joined(query)
+	matches := make([]Match, len(candidates))
+	for i, id := range candidates {
+		doc := x.docs[id]
+		score := weight / math.Sqrt(float64(doc.length))
+		if strings.Contains(doc.phrase, exact) || strings.Contains(doc.translation, exact) {
+			score *= 2
+		}
+		matches[i] = Match{x.phrases[id], score}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Phrase.Phrase < matches[j].Phrase.Phrase
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// intersect returns ids present in both sorted lists
+func intersect(a, b []int) []int {
+	var result []int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
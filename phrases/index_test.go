@@ -0,0 +1,112 @@
+package phrases_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/rpeshkov/slovnik"
+	"github.com/rpeshkov/slovnik/phrases"
+)
+
+func newIndex() *phrases.Index {
+	index := phrases.NewIndex(0)
+	index.Add("dobrý", slovnik.Cz, []slovnik.SampleUse{
+		{Keyword: "dobrý", Phrase: "dobrý den", Translation: "до́брый день"},
+		{Keyword: "dobrý", Phrase: "dobrý večer", Translation: "до́брый ве́чер"},
+		{Keyword: "dobrý", Phrase: "mít dobrý den v práci", Translation: "хорошо́ провести́ день на рабо́те"},
+	})
+	index.Add("den", slovnik.Cz, []slovnik.SampleUse{
+		{Keyword: "den", Phrase: "dobrý den", Translation: "до́брый день"},
+		{Keyword: "den", Phrase: "den a noc", Translation: "день и ночь"},
+	})
+	return index
+}
+
+func phraseList(matches []phrases.Match) []string {
+	var result []string
+	for _, m := range matches {
+		result = append(result, m.Phrase.Phrase)
+	}
+	return result
+}
+
+func TestSearch(t *testing.T) {
+	index := newIndex()
+
+	if index.Len() != 4 {
+		t.Errorf("Len() == %d, want 4", index.Len())
+	}
+
+	cases := []struct {
+		query    string
+		limit    int
+		expected []string
+	}{
+		{"dobry den", 10, []string{"dobrý den", "mít dobrý den v práci"}},
+		{"DEN dobrý", 10, []string{"dobrý den", "mít dobrý den v práci"}},
+		{"добрый", 10, []string{"dobrý den", "dobrý večer"}},
+		{"ве́чер", 10, []string{"dobrý večer"}},
+		{"день", 2, []string{"dobrý den", "den a noc"}},
+		{"noc večer", 10, nil},
+		{"xyz", 10, nil},
+		{"...", 10, nil},
+	}
+
+	for _, c := range cases {
+		if got := phraseList(index.Search(c.query, c.limit)); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("Search(%q) == %q, want %q", c.query, got, c.expected)
+		}
+	}
+
+	m := index.Search("večer", 1)
+	if len(m) != 1 || m[0].Headword != "dobrý" || m[0].Language != slovnik.Cz || m[0].Translation != "до́брый ве́чер" {
+		t.Errorf("Search(večer) == %+v, want phrase of dobrý", m)
+	}
+}
+
+func TestIndexLimit(t *testing.T) {
+	index := phrases.NewIndex(2)
+	index.Add("den", slovnik.Cz, []slovnik.SampleUse{
+		{Keyword: "den", Phrase: "dobrý den", Translation: "до́брый день"},
+		{Keyword: "den", Phrase: "den a noc", Translation: "день и ночь"},
+		{Keyword: "den", Phrase: "celý den", Translation: "весь день"},
+	})
+	index.Add("noc", slovnik.Cz, []slovnik.SampleUse{
+		{Keyword: "noc", Phrase: "dobrou noc", Translation: "споко́йной но́чи"},
+	})
+
+	if index.Len() != 2 {
+		t.Errorf("Len() == %d, want 2", index.Len())
+	}
+	if got := phraseList(index.Search("den", 10)); !reflect.DeepEqual(got, []string{"dobrý den", "den a noc"}) {
+		t.Errorf("Search(den) == %q, want phrases added before the limit", got)
+	}
+}
+
+type dictionary map[string][]*slovnik.Word
+
+func (d dictionary) Translate(ctx context.Context, word string, language slovnik.Language) ([]*slovnik.Word, error) {
+	return d[word], nil
+}
+
+func TestTranslatorIndexesSamples(t *testing.T) {
+	index := phrases.NewIndex(0)
+	translator := phrases.NewTranslator(dictionary{
+		"noc": {{Word: "noc", Samples: []slovnik.SampleUse{{Keyword: "noc", Phrase: "dobrou noc", Translation: "споко́йной но́чи"}}}},
+	}, index)
+
+	if got := index.Search("noc", 10); len(got) != 0 {
+		t.Errorf("Search() before lookup == %+v, want nothing", got)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := translator.Translate(context.Background(), "noc", slovnik.Cz); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := phraseList(index.Search("спокойной", 10)); !reflect.DeepEqual(got, []string{"dobrou noc"}) {
+		t.Errorf("Search(спокойной) == %q, want phrase of looked up entry once", got)
+	}
+}
@@ -0,0 +1,31 @@
+package phrases
+
+import (
+	"context"
+
+	"github.com/rpeshkov/slovnik"
+)
+
+// Translator adds sample phrases of entries found by translator it wraps to the index
+type Translator struct {
+	translator slovnik.Translator
+	index      *Index
+}
+
+// NewTranslator creates translator that indexes phrases of provided one
+func NewTranslator(translator slovnik.Translator, index *Index) *Translator {
+	return &Translator{translator, index}
+}
+
+// Translate translates the word and indexes sample phrases of the result
+func (t *Translator) Translate(ctx context.Context, word string, language slovnik.Language) ([]*slovnik.Word, error) {
+	words, err := t.translator.Translate(ctx, word, language)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range words {
+		t.index.Add(w.Word, language, w.Samples)
+	}
+	return words, nil
+}
@@ -1,22 +1,33 @@
 package seznam
 
 import (
+	"context"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"path"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rpeshkov/slovnik"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
-	scheme     = "https"
-	seznamHost = "slovnik.seznam.cz"
+	// DefaultURL is an address of slovnik.seznam.cz portal
+	DefaultURL = "https://slovnik.seznam.cz"
 
 	wordQueryVar      = "q"
 	shortViewQueryVar = "shortView"
 )
 
+// tracer creates spans of requests to the portal and parsing of its pages
+var tracer = otel.Tracer("github.com/rpeshkov/slovnik/seznam")
+
 // Map of urls used for translation
 var urls = map[slovnik.Language]string{
 	slovnik.Cz: "cz-ru",
@@ -24,11 +35,19 @@ var urls = map[slovnik.Language]string{
 }
 
 type Client struct {
-	client *http.Client
+	client  *http.Client
+	baseURL url.URL
+	logger  *slog.Logger
 }
 
 // NewClient creates a client for accessing slovnik.seznam.cz portal
 func NewClient(httpClient *http.Client) *Client {
+	c, _ := NewClientWithURL(httpClient, DefaultURL)
+	return c
+}
+
+// NewClientWithURL creates a client for the portal at provided address, e.g. a mirror
+func NewClientWithURL(httpClient *http.Client, baseURL string) (*Client, error) {
 	var c *http.Client
 
 	if httpClient == nil {
@@ -37,20 +56,63 @@ func NewClient(httpClient *http.Client) *Client {
 		c = httpClient
 	}
 
-	return &Client{
-		client: c,
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "bad seznam URL")
 	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, errors.Errorf("seznam URL %q must be absolute", baseURL)
+	}
+
+	return &Client{
+		client:  c,
+		baseURL: *u,
+		logger:  slog.Default(),
+	}, nil
+}
+
+// SetLogger sets logger of upstream requests
+func (c *Client) SetLogger(logger *slog.Logger) {
+	c.logger = logger
 }
 
 // Get requests translation result page for provided word
-func (c *Client) Get(word string, language slovnik.Language) (io.ReadCloser, error) {
+func (c *Client) Get(ctx context.Context, word string, language slovnik.Language) (io.ReadCloser, error) {
+	ctx, span := tracer.Start(ctx, "seznam.Get", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("word", word), attribute.String("direction", language.String())))
+	defer span.End()
+
 	query := c.createURL(word, language)
-	resp, err := c.client.Get(query.String())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, query.String(), nil)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, errors.Wrap(err, "bad request")
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
 
 	if err != nil {
+		c.logger.Warn("seznam request failed", "word", word, "direction", language.String(),
+			"latency", time.Since(start), "err", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, errors.Wrap(err, "get failed")
 	}
 
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+
+	// Error pages of the portal have no results, they must not pass for unknown words
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		c.logger.Warn("seznam request failed", "word", word, "direction", language.String(),
+			"status", resp.StatusCode, "latency", time.Since(start))
+		span.SetStatus(codes.Error, resp.Status)
+		return nil, errors.Errorf("seznam responded with status %d", resp.StatusCode)
+	}
+
+	c.logger.Debug("seznam request", "word", word, "direction", language.String(),
+		"status", resp.StatusCode, "latency", time.Since(start))
 	return resp.Body, nil
 }
 
@@ -59,10 +121,8 @@ func (c *Client) createURL(word string, language slovnik.Language) url.URL {
 	v.Add(wordQueryVar, word)
 	v.Add(shortViewQueryVar, "0")
 
-	return url.URL{
-		Scheme:   scheme,
-		Host:     seznamHost,
-		Path:     urls[language],
-		RawQuery: v.Encode(),
-	}
+	u := c.baseURL
+	u.Path = path.Join("/", u.Path, urls[language])
+	u.RawQuery = v.Encode()
+	return u
 }
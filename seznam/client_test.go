@@ -0,0 +1,47 @@
+package seznam_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rpeshkov/slovnik"
+	"github.com/rpeshkov/slovnik/seznam"
+)
+
+func TestClientStatus(t *testing.T) {
+	cases := []struct {
+		status int
+		valid  bool
+	}{
+		{http.StatusOK, true},
+		{http.StatusTooManyRequests, false},
+		{http.StatusInternalServerError, false},
+		{http.StatusServiceUnavailable, false},
+		{http.StatusNotFound, false},
+	}
+
+	for _, c := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(c.status)
+			w.Write([]byte("<html><body><div id=\"results\"></div></body></html>"))
+		}))
+
+		client, err := seznam.NewClientWithURL(nil, server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := client.Get(context.Background(), "slovo", slovnik.Cz)
+		if (err == nil) != c.valid {
+			t.Errorf("Get() with status %d error == %v, want valid %v", c.status, err, c.valid)
+		}
+		if body != nil {
+			ioutil.ReadAll(body)
+			body.Close()
+		}
+		server.Close()
+	}
+}
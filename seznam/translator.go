@@ -1,12 +1,25 @@
 package seznam
 
 import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+
 	"github.com/rpeshkov/slovnik"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
+// Getter fetches result pages of the portal. Client is the default implementation, other
+// ones may wrap it, e.g. to collect metrics.
+type Getter interface {
+	Get(ctx context.Context, word string, language slovnik.Language) (io.ReadCloser, error)
+}
+
 // Translator represents seznam translator type
 type Translator struct {
-	client *Client
+	client Getter
 	parser *Parser
 }
 
@@ -18,9 +31,24 @@ func NewTranslator() *Translator {
 	}
 }
 
+// NewTranslatorWithClient creates seznam translator that uses provided client
+func NewTranslatorWithClient(client Getter) *Translator {
+	return &Translator{
+		client: client,
+		parser: NewParser(),
+	}
+}
+
+// SetLogger sets logger of upstream requests when the client supports logging
+func (t *Translator) SetLogger(logger *slog.Logger) {
+	if c, ok := t.client.(interface{ SetLogger(*slog.Logger) }); ok {
+		c.SetLogger(logger)
+	}
+}
+
 // Translate translates provided word and returns results
-func (t *Translator) Translate(word string, language slovnik.Language) ([]*slovnik.Word, error) {
-	body, err := t.client.Get(word, language)
+func (t *Translator) Translate(ctx context.Context, word string, language slovnik.Language) ([]*slovnik.Word, error) {
+	body, err := t.client.Get(ctx, word, language)
 
 	if err != nil {
 		return nil, err
@@ -28,5 +56,45 @@ func (t *Translator) Translate(word string, language slovnik.Language) ([]*slovn
 
 	defer body.Close()
 
-	return t.parser.Parse(body)
+	_, span := tracer.Start(ctx, "seznam.Parse")
+	defer span.End()
+
+	words, err := t.parser.Parse(body)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("results", len(words)))
+	return words, nil
+}
+
+// Suggest suggests words of the portal for the prefix. Portal shows the entry when prefix is
+// a word itself, otherwise it lists similar words. Words that start with the prefix go first.
+func (t *Translator) Suggest(ctx context.Context, prefix string, language slovnik.Language, limit int) ([]string, error) {
+	words, err := t.Translate(ctx, prefix, language)
+	if err != nil {
+		return nil, err
+	}
+
+	folded := slovnik.Fold(prefix)
+	seen := make(map[string]bool)
+	var matching, similar []string
+	for _, w := range words {
+		if w.Word == "" || seen[w.Word] {
+			continue
+		}
+		seen[w.Word] = true
+
+		if strings.HasPrefix(slovnik.Fold(w.Word), folded) {
+			matching = append(matching, w.Word)
+		} else {
+			similar = append(similar, w.Word)
+		}
+	}
+
+	result := append(matching, similar...)
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
 }
@@ -0,0 +1,44 @@
+package seznam_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/rpeshkov/slovnik"
+	"github.com/rpeshkov/slovnik/seznam"
+)
+
+// fileGetter returns saved page of the portal instead of requesting it
+type fileGetter string
+
+func (f fileGetter) Get(ctx context.Context, word string, language slovnik.Language) (io.ReadCloser, error) {
+	return os.Open(string(f))
+}
+
+func TestSuggest(t *testing.T) {
+	cases := []struct {
+		page     string
+		prefix   string
+		limit    int
+		expected []string
+	}{
+		{"./test/sample_multiple_results.html", "dobr", 6, []string{"dobrat se", "dobrý", "dobro", "dobré", "dobrat", "doba"}},
+		{"./test/sample_multiple_results.html", "DOBR", 2, []string{"dobrat se", "dobrý"}},
+		{"./test/sample.html", "hlavni", 10, []string{"hlavní"}},
+	}
+
+	for _, c := range cases {
+		translator := seznam.NewTranslatorWithClient(fileGetter(c.page))
+		got, err := translator.Suggest(context.Background(), c.prefix, slovnik.Cz, c.limit)
+		if err != nil {
+			t.Errorf("Suggest(%q) failed: %v", c.prefix, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("Suggest(%q) == %q, want %q", c.prefix, got, c.expected)
+		}
+	}
+}
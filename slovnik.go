@@ -9,6 +9,12 @@ type Word struct {
 	Antonyms     []string    `json:"antonyms"`
 	DerivedWords []string    `json:"derived_words"`
 	Samples      []SampleUse `json:"samples"`
+
+	// Lemma is set when the word is found by the base form of requested inflected form
+	Lemma string `json:"lemma,omitempty"`
+
+	// Form is the requested inflected form when the word is found by its lemma
+	Form string `json:"form,omitempty"`
 }
 
 // SampleUse describes example phrase in which word can be used
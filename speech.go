@@ -0,0 +1,20 @@
+package slovnik
+
+import (
+	"context"
+	"io"
+)
+
+// SpeechRecognizer converts recorded speech to text
+type SpeechRecognizer interface {
+	// Recognize returns the text spoken in provided audio. Audio is expected to be in
+	// OGG container with Opus codec, the format telegram uses for voice messages.
+	Recognize(ctx context.Context, audio io.Reader) (string, error)
+}
+
+// SpeechSynthesizer converts text to speech
+type SpeechSynthesizer interface {
+	// Synthesize returns pronunciation of the text in provided language. Audio is returned in
+	// OGG container with Opus codec, so it can be sent as telegram voice message.
+	Synthesize(ctx context.Context, text string, language Language) ([]byte, error)
+}
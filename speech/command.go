@@ -0,0 +1,43 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultTimeout limits the time a speech engine may run
+const DefaultTimeout = 30 * time.Second
+
+// run executes command and returns its output. Error output is added to the error. The
+// command is killed when context is done.
+func run(ctx context.Context, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// Children of the killed command may still hold its output open
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", errors.Wrap(ctx.Err(), name)
+		}
+		return "", errors.Wrap(err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// withTimeout limits context with timeout unless it's zero
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
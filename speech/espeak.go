@@ -0,0 +1,72 @@
+package speech
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rpeshkov/slovnik"
+)
+
+// espeak voices for supported languages
+var espeakVoices = map[slovnik.Language]string{
+	slovnik.Cz: "cs",
+	slovnik.Ru: "ru",
+}
+
+// EspeakSynthesizer pronounces words with espeak-ng. Speech is encoded to Opus with ffmpeg.
+type EspeakSynthesizer struct {
+	// Binary is a path to espeak-ng executable
+	Binary string
+
+	// FFmpeg is a path to ffmpeg executable
+	FFmpeg string
+
+	// Timeout limits the time of synthesis and audio conversion together, zero means
+	// no limit
+	Timeout time.Duration
+}
+
+// NewEspeakSynthesizer creates synthesizer that uses provided espeak-ng executable.
+// ffmpeg is looked up in PATH.
+func NewEspeakSynthesizer(binary string) *EspeakSynthesizer {
+	return &EspeakSynthesizer{
+		Binary:  binary,
+		FFmpeg:  "ffmpeg",
+		Timeout: DefaultTimeout,
+	}
+}
+
+// Synthesize returns pronunciation of the text
+func (e *EspeakSynthesizer) Synthesize(ctx context.Context, text string, language slovnik.Language) ([]byte, error) {
+	voice, ok := espeakVoices[language]
+	if !ok {
+		return nil, errors.Errorf("language %d is not supported", language)
+	}
+
+	ctx, cancel := withTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	dir, err := ioutil.TempDir("", "slovnik-speech")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	wav := filepath.Join(dir, "speech.wav")
+	ogg := filepath.Join(dir, "speech.ogg")
+
+	// Text is passed after "--", so it's never treated as an option
+	if _, err = run(ctx, e.Binary, "-v", voice, "-s", "140", "-w", wav, "--", text); err != nil {
+		return nil, errors.Wrap(err, "synthesis failed")
+	}
+
+	if _, err = run(ctx, e.FFmpeg, "-nostdin", "-loglevel", "error", "-i", wav, "-c:a", "libopus", "-b:a", "32k", ogg); err != nil {
+		return nil, errors.Wrap(err, "audio conversion failed")
+	}
+
+	return ioutil.ReadFile(ogg)
+}
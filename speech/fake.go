@@ -0,0 +1,43 @@
+package speech
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+
+	"github.com/rpeshkov/slovnik"
+)
+
+// FakeRecognizer returns predefined text for any audio. It's intended for tests.
+type FakeRecognizer struct {
+	Text string
+	Err  error
+
+	// Audio contains the last recognized audio
+	Audio []byte
+}
+
+// Recognize returns predefined text
+func (f *FakeRecognizer) Recognize(ctx context.Context, audio io.Reader) (string, error) {
+	data, err := ioutil.ReadAll(audio)
+	if err != nil {
+		return "", err
+	}
+	f.Audio = data
+	return f.Text, f.Err
+}
+
+// FakeSynthesizer returns predefined audio for any text. It's intended for tests.
+type FakeSynthesizer struct {
+	Audio []byte
+	Err   error
+
+	// Calls counts synthesized texts
+	Calls int
+}
+
+// Synthesize returns predefined audio
+func (f *FakeSynthesizer) Synthesize(ctx context.Context, text string, language slovnik.Language) ([]byte, error) {
+	f.Calls++
+	return f.Audio, f.Err
+}
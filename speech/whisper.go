@@ -0,0 +1,79 @@
+// Package speech contains speech engines that run locally without network access
+package speech
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WhisperRecognizer recognizes speech with whisper.cpp command line tool. Audio is converted to
+// the format whisper expects with ffmpeg.
+type WhisperRecognizer struct {
+	// Binary is a path to whisper.cpp executable
+	Binary string
+
+	// Model is a path to ggml model file
+	Model string
+
+	// FFmpeg is a path to ffmpeg executable
+	FFmpeg string
+
+	// Timeout limits the time of audio conversion and recognition together, zero means
+	// no limit
+	Timeout time.Duration
+}
+
+// NewWhisperRecognizer creates recognizer that uses provided model. Executables are looked up
+// in PATH.
+func NewWhisperRecognizer(model string) *WhisperRecognizer {
+	return &WhisperRecognizer{
+		Binary:  "whisper-cli",
+		Model:   model,
+		FFmpeg:  "ffmpeg",
+		Timeout: DefaultTimeout,
+	}
+}
+
+// Recognize returns the text spoken in provided audio
+func (w *WhisperRecognizer) Recognize(ctx context.Context, audio io.Reader) (string, error) {
+	ctx, cancel := withTimeout(ctx, w.Timeout)
+	defer cancel()
+
+	dir, err := ioutil.TempDir("", "slovnik-speech")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input.ogg")
+	wav := filepath.Join(dir, "input.wav")
+
+	f, err := os.Create(input)
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(f, audio)
+	f.Close()
+	if err != nil {
+		return "", errors.Wrap(err, "unable to save audio")
+	}
+
+	// whisper accepts only 16kHz mono wav
+	if _, err = run(ctx, w.FFmpeg, "-nostdin", "-loglevel", "error", "-i", input, "-ar", "16000", "-ac", "1", wav); err != nil {
+		return "", errors.Wrap(err, "audio conversion failed")
+	}
+
+	out, err := run(ctx, w.Binary, "-m", w.Model, "-l", "auto", "--no-timestamps", "-f", wav)
+	if err != nil {
+		return "", errors.Wrap(err, "recognition failed")
+	}
+
+	return strings.Join(strings.Fields(out), " "), nil
+}
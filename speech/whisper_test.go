@@ -0,0 +1,86 @@
+package speech_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rpeshkov/slovnik"
+	"github.com/rpeshkov/slovnik/speech"
+)
+
+// script creates executable shell script in dir
+func script(t *testing.T, dir, name, body string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestWhisperRecognizer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "whisper-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// ffmpeg stub copies input to output, whisper stub prints the text
+	r := &speech.WhisperRecognizer{
+		FFmpeg: script(t, dir, "ffmpeg", `for a; do out="$a"; done; cp "$5" "$out"`),
+		Binary: script(t, dir, "whisper", `echo "  hlavní"; echo " město "`),
+		Model:  "model.bin",
+	}
+
+	text, err := r.Recognize(context.Background(), strings.NewReader("OggS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const want = "hlavní město"
+	if text != want {
+		t.Errorf("Recognize() == %q, want %q", text, want)
+	}
+
+	r.Binary = script(t, dir, "broken", `echo "model not found" >&2; exit 1`)
+	if _, err = r.Recognize(context.Background(), strings.NewReader("OggS")); err == nil || !strings.Contains(err.Error(), "model not found") {
+		t.Errorf("Recognize() error == %v, want error with engine output", err)
+	}
+}
+
+func TestSpeechTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "speech-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The stub leaves child process behind, it must not keep the engine waiting either
+	sleep := script(t, dir, "sleep", "sleep 10 & sleep 10")
+	r := &speech.WhisperRecognizer{FFmpeg: sleep, Binary: sleep, Timeout: 100 * time.Millisecond}
+	s := &speech.EspeakSynthesizer{FFmpeg: sleep, Binary: sleep, Timeout: 100 * time.Millisecond}
+
+	start := time.Now()
+	if _, err = r.Recognize(context.Background(), strings.NewReader("OggS")); errors.Cause(err) != context.DeadlineExceeded {
+		t.Errorf("Recognize() error == %v, want deadline exceeded", err)
+	}
+	if _, err = s.Synthesize(context.Background(), "hlavní", slovnik.Cz); errors.Cause(err) != context.DeadlineExceeded {
+		t.Errorf("Synthesize() error == %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("engines stopped after %v, want them killed on timeout", elapsed)
+	}
+
+	// Context of the update is respected as well
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.Timeout = 0
+	if _, err = r.Recognize(ctx, strings.NewReader("OggS")); errors.Cause(err) != context.Canceled {
+		t.Errorf("Recognize() error == %v, want context canceled", err)
+	}
+}
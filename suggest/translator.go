@@ -0,0 +1,62 @@
+package suggest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rpeshkov/slovnik"
+)
+
+// Translator remembers headwords of entries found by translator it wraps and suggests them.
+// Suggestions don't need requests to the dictionary, but only words looked up since start
+// are known, up to the limit in each language.
+type Translator struct {
+	translator slovnik.Translator
+	limit      int
+
+	mu    sync.RWMutex
+	tries map[slovnik.Language]*Trie
+}
+
+// NewTranslator creates translator that collects up to limit headwords of provided one in
+// each language, zero means no limit
+func NewTranslator(translator slovnik.Translator, limit int) *Translator {
+	return &Translator{
+		translator: translator,
+		limit:      limit,
+		tries:      make(map[slovnik.Language]*Trie),
+	}
+}
+
+// Translate translates the word and remembers headwords of the result
+func (t *Translator) Translate(ctx context.Context, word string, language slovnik.Language) ([]*slovnik.Word, error) {
+	words, err := t.translator.Translate(ctx, word, language)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	trie, ok := t.tries[language]
+	if !ok {
+		trie = NewTrie(t.limit)
+		t.tries[language] = trie
+	}
+	for _, w := range words {
+		trie.Insert(w.Word)
+	}
+	return words, nil
+}
+
+// Suggest returns known headwords that start with the prefix
+func (t *Translator) Suggest(ctx context.Context, prefix string, language slovnik.Language, limit int) ([]string, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	trie, ok := t.tries[language]
+	if !ok {
+		return nil, nil
+	}
+	return trie.Prefix(prefix, limit), nil
+}
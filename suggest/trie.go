@@ -0,0 +1,107 @@
+// Package suggest suggests words that were seen in the dictionary before
+package suggest
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/rpeshkov/slovnik"
+)
+
+// Trie is a prefix tree of words. Words are stored by their folded form, so prefixes match
+// them regardless of case and accents. Number of words may be limited, words inserted after
+// the limit is reached are ignored. Trie isn't safe for concurrent use.
+type Trie struct {
+	root  node
+	size  int
+	limit int
+}
+
+type node struct {
+	children map[rune]*node
+
+	// words end at the node, there may be several of them with the same folded form
+	words []string
+}
+
+// NewTrie creates empty trie that keeps up to limit words, zero means no limit
+func NewTrie(limit int) *Trie {
+	return &Trie{limit: limit}
+}
+
+// Len returns number of words in the trie
+func (t *Trie) Len() int {
+	return t.size
+}
+
+// Insert adds the word to the trie, words that are already there are ignored
+func (t *Trie) Insert(word string) {
+	word = strings.TrimSpace(word)
+	key := slovnik.Fold(word)
+	if key == "" || t.full() {
+		return
+	}
+
+	n := &t.root
+	for _, r := range key {
+		child, ok := n.children[r]
+		if !ok {
+			if n.children == nil {
+				n.children = make(map[rune]*node)
+			}
+			child = &node{}
+			n.children[r] = child
+		}
+		n = child
+	}
+
+	for _, w := range n.words {
+		if w == word {
+			return
+		}
+	}
+	n.words = append(n.words, word)
+	sort.Strings(n.words)
+	t.size++
+}
+
+func (t *Trie) full() bool {
+	return t.limit > 0 && t.size >= t.limit
+}
+
+// Prefix returns up to limit words that start with the prefix. Shorter words go first,
+// words of the same length are ordered alphabetically by their folded form.
+func (t *Trie) Prefix(prefix string, limit int) []string {
+	n := &t.root
+	for _, r := range slovnik.Fold(prefix) {
+		n = n.children[r]
+		if n == nil {
+			return nil
+		}
+	}
+
+	var result []string
+	level := []*node{n}
+	for len(level) > 0 && len(result) < limit {
+		var next []*node
+		for _, n := range level {
+			for _, w := range n.words {
+				if len(result) == limit {
+					return result
+				}
+				result = append(result, w)
+			}
+
+			keys := make([]rune, 0, len(n.children))
+			for r := range n.children {
+				keys = append(keys, r)
+			}
+			sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+			for _, r := range keys {
+				next = append(next, n.children[r])
+			}
+		}
+		level = next
+	}
+	return result
+}
@@ -0,0 +1,115 @@
+package suggest_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/rpeshkov/slovnik"
+	"github.com/rpeshkov/slovnik/suggest"
+)
+
+func TestTriePrefix(t *testing.T) {
+	trie := suggest.NewTrie(0)
+	for _, w := range []string{"dobrý", "dobro", "dobrat se", "doba", "dobrý", "Dobrý", "", "čas", "часы", "час"} {
+		trie.Insert(w)
+	}
+
+	if trie.Len() != 8 {
+		t.Errorf("Len() == %d, want 8", trie.Len())
+	}
+
+	cases := []struct {
+		prefix   string
+		limit    int
+		expected []string
+	}{
+		{"dobr", 10, []string{"dobro", "Dobrý", "dobrý", "dobrat se"}},
+		{"DOBRY", 10, []string{"Dobrý", "dobrý"}},
+		{"dob", 2, []string{"doba", "dobro"}},
+		{"cas", 10, []string{"čas"}},
+		{"час", 10, []string{"час", "часы"}},
+		{"xyz", 10, nil},
+	}
+
+	for _, c := range cases {
+		if got := trie.Prefix(c.prefix, c.limit); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("Prefix(%q, %d) == %q, want %q", c.prefix, c.limit, got, c.expected)
+		}
+	}
+}
+
+type dictionary map[string][]*slovnik.Word
+
+func (d dictionary) Translate(ctx context.Context, word string, language slovnik.Language) ([]*slovnik.Word, error) {
+	return d[word], nil
+}
+
+func TestTranslatorSuggest(t *testing.T) {
+	translator := suggest.NewTranslator(dictionary{
+		"dobrý": {{Word: "dobrý"}},
+		"dobr":  {{Word: "dobro"}, {Word: "bobr"}},
+		"час":   {{Word: "час"}},
+	}, 0)
+
+	ctx := context.Background()
+	if got, _ := translator.Suggest(ctx, "dob", slovnik.Cz, 10); len(got) != 0 {
+		t.Errorf("Suggest() before lookups == %q, want nothing", got)
+	}
+
+	translator.Translate(ctx, "dobrý", slovnik.Cz)
+	translator.Translate(ctx, "dobr", slovnik.Cz)
+	translator.Translate(ctx, "час", slovnik.Ru)
+
+	cases := []struct {
+		prefix   string
+		language slovnik.Language
+		expected []string
+	}{
+		{"dob", slovnik.Cz, []string{"dobro", "dobrý"}},
+		{"bo", slovnik.Cz, []string{"bobr"}},
+		{"ча", slovnik.Cz, nil},
+		{"ча", slovnik.Ru, []string{"час"}},
+	}
+
+	for _, c := range cases {
+		got, err := translator.Suggest(ctx, c.prefix, c.language, 10)
+		if err != nil || !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("Suggest(%q, %v) == %q, %v, want %q", c.prefix, c.language, got, err, c.expected)
+		}
+	}
+}
+
+func TestTrieLimit(t *testing.T) {
+	trie := suggest.NewTrie(2)
+	for _, w := range []string{"doba", "dobro", "dobro", "dobrý"} {
+		trie.Insert(w)
+	}
+
+	if trie.Len() != 2 {
+		t.Errorf("Len() == %d, want 2", trie.Len())
+	}
+	if got := trie.Prefix("dob", 10); !reflect.DeepEqual(got, []string{"doba", "dobro"}) {
+		t.Errorf("Prefix(dob) == %q, want words inserted before the limit", got)
+	}
+}
+
+func TestTranslatorLimit(t *testing.T) {
+	translator := suggest.NewTranslator(dictionary{
+		"dobr": {{Word: "dobro"}, {Word: "bobr"}},
+		"čas":  {{Word: "čas"}},
+		"час":  {{Word: "час"}},
+	}, 2)
+
+	ctx := context.Background()
+	translator.Translate(ctx, "dobr", slovnik.Cz)
+	translator.Translate(ctx, "čas", slovnik.Cz)
+	translator.Translate(ctx, "час", slovnik.Ru)
+
+	if got, _ := translator.Suggest(ctx, "ca", slovnik.Cz, 10); len(got) != 0 {
+		t.Errorf("Suggest(ca) == %q, want nothing after the limit", got)
+	}
+	if got, _ := translator.Suggest(ctx, "ча", slovnik.Ru, 10); !reflect.DeepEqual(got, []string{"час"}) {
+		t.Errorf("Suggest(ча) == %q, want limit counted in each language", got)
+	}
+}
@@ -0,0 +1,32 @@
+package slovnik
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenize splits input into words. Punctuation and digits are dropped, hyphens and
+// apostrophes are kept when they are inside of the word. Repeated words are returned once,
+// words are compared case insensitively and the first occurrence is kept.
+func Tokenize(input string) []string {
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.Is(unicode.Mn, r) && r != '-' && r != '\''
+	})
+
+	seen := map[string]bool{}
+	tokens := []string{}
+	for _, f := range fields {
+		f = strings.Trim(f, "-'")
+		if f == "" {
+			continue
+		}
+
+		key := strings.ToLower(f)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		tokens = append(tokens, f)
+	}
+	return tokens
+}
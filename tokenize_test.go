@@ -0,0 +1,29 @@
+package slovnik_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rpeshkov/slovnik"
+)
+
+func TestTokenize(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{"hlavní", []string{"hlavní"}},
+		{"Praha je hlavní město, hlavní!", []string{"Praha", "je", "hlavní", "město"}},
+		{"Москва — столица России.", []string{"Москва", "столица", "России"}},
+		{"kdo-koli 3 -- 'dobře'", []string{"kdo-koli", "dobře"}},
+		{"гла́вный", []string{"гла́вный"}},
+		{" ...  ", []string{}},
+	}
+
+	for _, c := range cases {
+		got := slovnik.Tokenize(c.in)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Tokenize(%q) == %q, want %q", c.in, got, c.want)
+		}
+	}
+}
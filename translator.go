@@ -1,5 +1,15 @@
 package slovnik
 
+import "context"
+
+// Translator translates words. Context carries deadline and trace of the request that
+// needs translation.
 type Translator interface {
-	Translate(word string, language Language) ([]*Word, error)
+	Translate(ctx context.Context, word string, language Language) ([]*Word, error)
+}
+
+// Suggester suggests words while user types them. Suggestions usually start with the prefix,
+// but they may also be similar words when nothing starts with it.
+type Suggester interface {
+	Suggest(ctx context.Context, prefix string, language Language, limit int) ([]string, error)
 }